@@ -0,0 +1,117 @@
+package aaronsql
+
+import (
+	"context"
+	"fmt"
+)
+
+// TableCheck is a named CHECK constraint of a table.
+type TableCheck struct {
+	name string
+	// expression is the condition every row must satisfy, eg: price >= 0
+	expression string
+}
+
+func NewTableCheck(name, expression string) *TableCheck {
+	return &TableCheck{
+		name:       name,
+		expression: expression,
+	}
+}
+
+// Name returns the constraint name.
+func (c *TableCheck) Name() string {
+	return c.name
+}
+
+// Expression returns the condition of the constraint.
+func (c *TableCheck) Expression() string {
+	return c.expression
+}
+
+// equal compares the conditions of checks as normalized by normalizeSQLExpression, the
+// database prints them back with its own casts and parentheses.
+func (c *TableCheck) equal(other *TableCheck) bool {
+	return normalizeSQLExpression(c.expression) == normalizeSQLExpression(other.expression)
+}
+
+// WithCheck adds the table level CHECK constraint name to the table, eg:
+//
+//	NewTableFromStructWithDB(Order{}, "orders", "main", WithCheck("chk_orders_dates", "shipped_at >= created_at"))
+func WithCheck(name, expression string) TableOption {
+	return func(t *Table) {
+		t.checks = append(t.checks, TableCheck{name: name, expression: expression})
+	}
+}
+
+// Checks returns the CHECK constraints of the table.
+func (t *Table) Checks() []TableCheck {
+	return t.checks
+}
+
+// constructChecks names the CHECK constraints declared with WithCheck and adds the ones of the
+// check tags, named by the naming strategy. eg: db:"check:price >= 0".
+func (t *Table) constructChecks() error {
+	for i := range t.checks {
+		if t.checks[i].name == "" || t.checks[i].expression == "" {
+			return fmt.Errorf("check constraint of table %s needs a name and an expression", t.name)
+		}
+		t.checks[i].name = t.identifierName(t.checks[i].name)
+	}
+	for _, col := range t.columns {
+		expression, ok := col.GetStructTags()[TAG_CHECK]
+		if !ok {
+			continue
+		}
+		if expression == "" {
+			return fmt.Errorf("column %s: empty %s tag", col.Name(), TAG_CHECK)
+		}
+		name := t.identifierName(t.db.GetDB().NamingStrategy().CheckName(t.name, []string{col.Name()}))
+		t.checks = append(t.checks, TableCheck{name: name, expression: expression})
+	}
+	seen := make(map[string]bool, len(t.checks))
+	for _, check := range t.checks {
+		if seen[check.name] {
+			return fmt.Errorf("duplicate check constraint %s in table %s", check.name, t.name)
+		}
+		seen[check.name] = true
+	}
+	return nil
+}
+
+// syncChecks adds the CHECK constraints missing from existTable and replaces the ones whose
+// condition differs. Checks of existTable the table does not declare are left in place.
+func (t *Table) syncChecks(ctx context.Context, schema string, existTable *Table) error {
+	existingChecks := make(map[string]TableCheck, len(existTable.checks))
+	for _, check := range existTable.checks {
+		existingChecks[check.name] = check
+	}
+	for _, check := range t.checks {
+		existingCheck, exists := existingChecks[check.name]
+		if exists && existingCheck.equal(&check) {
+			continue
+		}
+		if exists {
+			if err := t.execCheckTemplate(ctx, schema, DropCheckTemplate, check); err != nil {
+				return fmt.Errorf("failed to drop check constraint %s from table %s: %w", check.name, t.name, err)
+			}
+		}
+		if err := t.execCheckTemplate(ctx, schema, AddCheckTemplate, check); err != nil {
+			return fmt.Errorf("failed to add check constraint %s to table %s: %w", check.name, t.name, err)
+		}
+	}
+	return nil
+}
+
+func (t *Table) execCheckTemplate(ctx context.Context, schema string, name SQLTemplateName, check TableCheck) error {
+	checkSQL, err := renderSQL(t.db, name, SQLTemplateData{
+		TableName:      t.qualifiedName(schema),
+		ConstraintName: t.db.QuoteIdentifier(check.name),
+		Expression:     check.expression,
+	})
+	if err != nil {
+		return err
+	}
+	_, err = t.db.GetDB().db.ExecContext(ctx, checkSQL)
+	return err
+}
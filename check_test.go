@@ -0,0 +1,119 @@
+package aaronsql
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+type checkOrder struct {
+	ID        int64     `db:"name:id;primary"`
+	Price     float64   `db:"name:price;check:price >= 0"`
+	CreatedAt time.Time `db:"name:created_at"`
+	ShippedAt time.Time `db:"name:shipped_at;nullable:true"`
+}
+
+func TestCheckConstraints(t *testing.T) {
+	registerTestDB(t, "check_postgres_test", &PostgresDataBase{DataBase: DataBase{name: PostgresDB}})
+	registerTestDB(t, "check_mariadb_test", &MariaDBDataBase{DataBase: DataBase{name: MariaDB}})
+
+	cases := []struct {
+		dbName   string
+		expected []string
+	}{
+		{"check_postgres_test", []string{
+			`, CONSTRAINT "chk_orders_dates" CHECK (shipped_at >= created_at)`,
+			`, CONSTRAINT "chk_orders_price" CHECK (price >= 0)`,
+		}},
+		{"check_mariadb_test", []string{
+			", CONSTRAINT `chk_orders_dates` CHECK (shipped_at >= created_at)",
+			", CONSTRAINT `chk_orders_price` CHECK (price >= 0)",
+		}},
+	}
+	for _, c := range cases {
+		table, err := NewTableFromStructWithDB(checkOrder{}, "orders", c.dbName, WithCheck("chk_orders_dates", "shipped_at >= created_at"))
+		if err != nil {
+			t.Fatalf("Failed to create table from struct: %v", err)
+		}
+		if checks := table.Checks(); len(checks) != 2 || checks[1].Name() != "chk_orders_price" || checks[1].Expression() != "price >= 0" {
+			t.Errorf("Expected the WithCheck and tag checks, got %+v", checks)
+		}
+		createSQL := table.db.GetCreateTableSQL("", table.name, table.columns, table.createTableOptions())
+		for _, expected := range c.expected {
+			if !strings.Contains(createSQL, expected) {
+				t.Errorf("Expected %s in %s", expected, createSQL)
+			}
+		}
+	}
+
+	if _, err := NewTableFromStructWithDB(checkOrder{}, "orders", "check_postgres_test", WithCheck("chk_orders_price", "price > 0")); err == nil {
+		t.Errorf("Expected an error for a duplicate check name")
+	}
+	if _, err := NewTableFromStructWithDB(checkOrder{}, "orders", "check_postgres_test", WithCheck("", "price > 0")); err == nil {
+		t.Errorf("Expected an error for a check without a name")
+	}
+}
+
+func TestTableCheckEqual(t *testing.T) {
+	declared := NewTableCheck("chk_orders_status", "status IN ('new', 'paid') AND price >= 0")
+	// PostgreSQL prints conditions back with casts and parentheses, MariaDB with backticks
+	introspected := []TableCheck{
+		{expression: postgresCheckExpression("CHECK (((status)::text = ANY (ARRAY['new'::text, 'paid'::text])) AND price >= 0::numeric) NOT VALID")},
+		{expression: "`status` in ('new','paid') and `price` >= 0"},
+	}
+	if !declared.equal(&introspected[1]) {
+		t.Errorf("Expected the MariaDB check to equal the declared one")
+	}
+	if got := introspected[0].Expression(); strings.HasPrefix(got, "CHECK") || strings.HasSuffix(got, "NOT VALID") {
+		t.Errorf("Expected the condition of the constraint definition, got %s", got)
+	}
+	if declared.equal(&TableCheck{expression: "status IN ('new') AND price >= 0"}) {
+		t.Errorf("Expected a changed condition to make the checks differ")
+	}
+}
+
+func TestRenderSQLCheck(t *testing.T) {
+	postgres := &PostgresDataBase{DataBase: DataBase{name: PostgresDB}}
+	data := SQLTemplateData{TableName: `"orders"`, ConstraintName: `"chk_orders_price"`, Expression: "price >= 0"}
+	got, err := renderSQL(postgres, AddCheckTemplate, data)
+	if err != nil {
+		t.Fatalf("Failed to render add check template: %v", err)
+	}
+	if expected := `ALTER TABLE "orders" ADD CONSTRAINT "chk_orders_price" CHECK (price >= 0);`; got != expected {
+		t.Errorf("Expected %s, got %s", expected, got)
+	}
+	got, err = renderSQL(postgres, DropCheckTemplate, data)
+	if err != nil {
+		t.Fatalf("Failed to render drop check template: %v", err)
+	}
+	if expected := `ALTER TABLE "orders" DROP CONSTRAINT IF EXISTS "chk_orders_price";`; got != expected {
+		t.Errorf("Expected %s, got %s", expected, got)
+	}
+}
+
+func TestSyncChecksChangedLiteral(t *testing.T) {
+	postgres := &PostgresDataBase{DataBase: DataBase{name: PostgresDB}}
+	conn := recordStatements(t, "check_sync_test", postgres)
+	table, err := NewTableFromStructWithDB(checkOrder{}, "orders", "check_sync_test",
+		WithCheck("chk_orders_code", "code <> 'ABC'"), WithCheck("chk_orders_dates", "shipped_at >= created_at"))
+	if err != nil {
+		t.Fatalf("Failed to create table from struct: %v", err)
+	}
+	// the database prints the literal back with a cast, only its case changed
+	existing := &Table{checks: []TableCheck{
+		{name: "chk_orders_code", expression: "((code)::text <> 'abc'::text)"},
+		{name: "chk_orders_dates", expression: "(shipped_at >= created_at)"},
+		{name: "chk_orders_price", expression: "(price >= (0)::double precision)"},
+	}}
+	if err := table.syncChecks(context.Background(), "", existing); err != nil {
+		t.Fatalf("Failed to sync checks: %v", err)
+	}
+	expected := []string{
+		`ALTER TABLE "orders" DROP CONSTRAINT IF EXISTS "chk_orders_code";`,
+		`ALTER TABLE "orders" ADD CONSTRAINT "chk_orders_code" CHECK (code <> 'ABC');`,
+	}
+	if strings.Join(conn.statements, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("Expected statements %v, got %v", expected, conn.statements)
+	}
+}
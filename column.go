@@ -2,7 +2,10 @@ package aaronsql
 
 import (
 	"fmt"
+	"reflect"
+	"regexp"
 	"strconv"
+	"strings"
 )
 
 type ColumnInterface interface {
@@ -38,6 +41,7 @@ type ColumnInterface interface {
 
 	ConvertFromStringToSQL(value string) interface{}
 	ConvertFromValueToSQL(value interface{}) interface{}
+	ScanTarget(field reflect.Value) interface{}
 
 	Extra() string
 	DefinitionSQL() string
@@ -45,6 +49,18 @@ type ColumnInterface interface {
 	SetColumnIndex(index int)
 
 	IsAutoVersion() bool
+	// GeneratedExpression returns the expression of a stored generated column, empty for
+	// regular columns. Generated columns are never written.
+	GeneratedExpression() string
+	SetGeneratedExpression(expression string)
+	// EnumValues returns the values of enum columns, nil for other columns.
+	EnumValues() []string
+	Comment() string
+	SetComment(comment string)
+	// Charset and Collation return the character set and collation of the column, empty for
+	// the defaults of the table
+	Charset() string
+	Collation() string
 
 	IsUpdatedAt() bool
 	IsCreatedAt() bool
@@ -61,8 +77,24 @@ type BaseColumn struct {
 	isUnique      bool
 	isIndex       bool
 	isAllowZero   bool
+	isSearchable  bool
+	generated     string
+	enumValues    []string
 	tags          map[string]string
 	columnIndex   int
+	converter     ValueConverter
+	parser        TextParser
+	isJSON        bool
+
+	// isAutoIncrement columns are generated by the database and skipped by Insert and Update,
+	// autoIncrementOffset is their first value
+	isAutoIncrement     bool
+	autoIncrementOffset int64
+	// comment is the column comment read by data catalogs, see TAG_COMMENT
+	comment string
+	// charset and collation override the defaults of the table, see TAG_CHARSET and TAG_COLLATE
+	charset   string
+	collation string
 }
 
 // Name returns the column name
@@ -135,9 +167,9 @@ func (c *BaseColumn) IsText() bool {
 	return false
 }
 
-// IsSearchable returns whether the column is searchable
+// IsSearchable returns whether the column is full-text searchable, see Query.Search
 func (c *BaseColumn) IsSearchable() bool {
-	return false
+	return c.isSearchable
 }
 
 // IsAscii returns whether the column contains ASCII data
@@ -157,20 +189,23 @@ func (c *BaseColumn) IsZero(v interface{}) bool {
 
 // IsAutoIncrement returns whether the column is auto-incrementing
 func (c *BaseColumn) IsAutoIncrement() bool {
-	return false
+	return c.isAutoIncrement
 }
 
 // SetAutoIncrement sets whether the column is auto-incrementing
 func (c *BaseColumn) SetAutoIncrement(isAutoIncrement bool) {
+	c.isAutoIncrement = isAutoIncrement
 }
 
-// AutoIncrementOffset returns the auto-increment offset
+// AutoIncrementOffset returns the first value of an auto-increment column, zero for the default
+// of 1. Introspected columns hold the current counter of the table instead.
 func (c *BaseColumn) AutoIncrementOffset() int64 {
-	return 0
+	return c.autoIncrementOffset
 }
 
 // SetAutoIncrementOffset sets the auto-increment offset
 func (c *BaseColumn) SetAutoIncrementOffset(offset int64) {
+	c.autoIncrementOffset = offset
 }
 
 // IsPointer returns whether the column is a pointer type
@@ -200,9 +235,35 @@ func (c *BaseColumn) ConvertFromStringToSQL(value string) interface{} {
 
 // ConvertFromValueToSQL converts a value to SQL format
 func (c *BaseColumn) ConvertFromValueToSQL(value interface{}) interface{} {
+	if c.converter != nil {
+		// Converters always receive the underlying value, nil pointers are stored as NULL
+		if rv := reflect.ValueOf(value); rv.Kind() == reflect.Ptr {
+			if rv.IsNil() {
+				return nil
+			}
+			value = rv.Elem().Interface()
+		}
+		return c.converter(value)
+	}
 	return value
 }
 
+// ScanTarget returns the destination passed to Rows.Scan to read the column into field
+func (c *BaseColumn) ScanTarget(field reflect.Value) interface{} {
+	if c.isJSON {
+		return &jsonScanner{dst: field}
+	}
+	if c.parser != nil {
+		return &textScanner{dst: field, parse: c.parser}
+	}
+	return field.Addr().Interface()
+}
+
+// IsJSON returns whether the column stores JSON documents
+func (c *BaseColumn) IsJSON() bool {
+	return c.isJSON
+}
+
 // Extra returns extra column information
 func (c *BaseColumn) Extra() string {
 	return ""
@@ -229,6 +290,38 @@ func (c *BaseColumn) IsAutoVersion() bool {
 	return false
 }
 
+// GeneratedExpression returns the expression of a stored generated column
+func (c *BaseColumn) GeneratedExpression() string {
+	return c.generated
+}
+
+// SetGeneratedExpression makes the column a stored generated column, empty makes it regular
+func (c *BaseColumn) SetGeneratedExpression(expression string) {
+	c.generated = expression
+}
+
+func (c *BaseColumn) EnumValues() []string {
+	return c.enumValues
+}
+
+// Comment returns the comment of the column, empty for none
+func (c *BaseColumn) Comment() string {
+	return c.comment
+}
+
+// SetComment sets the comment of the column
+func (c *BaseColumn) SetComment(comment string) {
+	c.comment = comment
+}
+
+func (c *BaseColumn) Charset() string {
+	return c.charset
+}
+
+func (c *BaseColumn) Collation() string {
+	return c.collation
+}
+
 // IsUpdatedAt returns whether the column is an updated_at timestamp column
 func (c *BaseColumn) IsUpdatedAt() bool {
 	return false
@@ -239,101 +332,193 @@ func (c *BaseColumn) IsCreatedAt() bool {
 	return false
 }
 
+// NewBaseColumn builds a column from the field tags. Columns are nullable when the field is a
+// pointer unless the nullable tag says otherwise, primary keys are never nullable. PostgreSQL
+// columns are NOT NULL unless tagged nullable, see PostgresDataBase.GetColumnDefinitionByType.
 func NewBaseColumn(name string, sqltype string, tagmap map[string]string, isPointer bool) BaseColumn {
-	var v string
-	var ok bool
-	if v, ok = tagmap[TAG_NAME]; ok {
+	if v, ok := tagmap[TAG_NAME]; ok && v != "" {
 		name = v
 	}
-	defaultStr := ""
-	if v, ok = tagmap[TAG_DEFAULT]; ok {
-		defaultStr = v
-	}
-	isNullable := true
-	if v, ok = tagmap[TAG_NULLABLE]; ok {
-		b, _ := strconv.ParseBool(v)
-		isNullable = b
-	}
-	isPrimaryKey := false
-	if v, ok = tagmap[TAG_PRIMARY]; ok {
-		if v == "" || v == "true" || v == "1" {
-			isPrimaryKey = true
-		} else {
-			b, _ := strconv.ParseBool(v)
-			isPrimaryKey = b
-		}
-	}
-	isUnique := false
-	if v, ok = tagmap[TAG_UNIQUE]; ok {
-		if v == "" || v == "true" || v == "1" {
-			isUnique = true
-		} else {
-			b, _ := strconv.ParseBool(v)
-			isUnique = b
-		}
-	}
-	isIndex := false
-	if v, ok = tagmap[TAG_INDEX]; ok {
-		if v == "" || v == "true" || v == "1" {
-			isIndex = true
-		} else {
-			b, _ := strconv.ParseBool(v)
-			isIndex = b
-		}
+	isNullable := isPointer
+	if _, ok := tagmap[TAG_NULLABLE]; ok {
+		isNullable = tagFlag(tagmap, TAG_NULLABLE)
 	}
+	isPrimaryKey := tagFlag(tagmap, TAG_PRIMARY)
 	if isPrimaryKey {
 		// If the column is a primary key, it cannot be nullable
 		isNullable = false
 	}
-	isAllowZero := false
-	if v, ok = tagmap[TAG_ALLOW_ZERO]; ok {
-		if v == "" || v == "true" || v == "1" {
-			isAllowZero = true
-		} else {
-			b, _ := strconv.ParseBool(v)
-			isAllowZero = b
-		}
+	isIndex := false
+	if v, ok := tagmap[TAG_INDEX]; ok {
+		// index takes an index name as well, only an explicit false disables it
+		b, err := strconv.ParseBool(v)
+		isIndex = err != nil || b
 	}
-	return BaseColumn{
+	isSearchable := false
+	if v, ok := tagmap[TAG_SEARCHABLE]; ok {
+		// searchable takes a text search configuration as well
+		b, err := strconv.ParseBool(v)
+		isSearchable = v == "" || err != nil || b
+	}
+	col := BaseColumn{
 		name:          name,
 		sqlType:       sqltype,
-		defaultString: defaultStr,
+		defaultString: tagmap[TAG_DEFAULT],
 		isPointer:     isPointer,
 		isNullable:    isNullable,
 		isPrimaryKey:  isPrimaryKey,
-		isUnique:      isUnique,
+		isUnique:      tagFlag(tagmap, TAG_UNIQUE),
 		isIndex:       isIndex,
-		isAllowZero:   isAllowZero,
+		isAllowZero:   tagFlag(tagmap, TAG_ALLOW_ZERO),
+		isSearchable:  isSearchable,
 		tags:          tagmap,
 		columnIndex:   -1, // Default index is -1, to be set later
 		oldName:       "",
 	}
+	// serial columns are auto-incrementing PostgreSQL columns
+	col.isAutoIncrement = tagFlag(tagmap, TAG_AUTO_INCREMENT) || tagFlag(tagmap, TAG_SERIAL)
+	col.autoIncrementOffset, _ = strconv.ParseInt(tagmap[TAG_AUTO_INCREMENT_OFFSET], 10, 64)
+	col.comment = unquoteTagValue(tagmap[TAG_COMMENT])
+	col.charset = tagmap[TAG_CHARSET]
+	col.collation = tagmap[TAG_COLLATE]
+	return col
+}
+
+// parsePrecision parses the precision tag, formatted as "p" or "p,s".
+func parsePrecision(tagmap map[string]string) (precision int, scale int, ok bool) {
+	v, exists := tagmap[TAG_PRECISION]
+	if !exists || v == "" {
+		return 0, 0, false
+	}
+	parts := strings.SplitN(v, ",", 2)
+	precision, err := strconv.Atoi(strings.TrimSpace(parts[0]))
+	if err != nil || precision <= 0 {
+		return 0, 0, false
+	}
+	if len(parts) == 2 {
+		if scale, err = strconv.Atoi(strings.TrimSpace(parts[1])); err != nil || scale < 0 {
+			return 0, 0, false
+		}
+	}
+	return precision, scale, true
 }
 
+// BaseWidthColumn is a column whose type takes arguments: the width of character types,
+// the precision and scale of decimal types and the fractional seconds precision of time types.
 type BaseWidthColumn struct {
 	BaseColumn
-	width int
+	width     int
+	precision int
+	scale     int
 }
 
+// widthTypes, decimalTypes and timeTypes classify the base types by the arguments they accept.
+var (
+	widthTypes   = map[string]bool{"VARCHAR": true, "CHAR": true, "VARBINARY": true, "BINARY": true}
+	decimalTypes = map[string]bool{"DECIMAL": true, "NUMERIC": true}
+	timeTypes    = map[string]bool{"DATETIME": true, "TIMESTAMP": true, "TIME": true, "TIMESTAMP WITH TIME ZONE": true, "TIME WITH TIME ZONE": true}
+)
+
+// ColType returns the column type with its width, precision and scale arguments.
 func (c *BaseWidthColumn) ColType() string {
-	if c.width > 0 {
-		return fmt.Sprintf("%s(%d)", c.Type(), c.width)
+	base := strings.ToUpper(c.sqlType)
+	switch {
+	case strings.Contains(c.sqlType, "("):
+		return c.sqlType
+	case widthTypes[base] && c.width > 0:
+		return withTypeArgs(c.sqlType, strconv.Itoa(c.width))
+	case decimalTypes[base] && c.precision > 0:
+		return withTypeArgs(c.sqlType, fmt.Sprintf("%d,%d", c.precision, c.scale))
+	case timeTypes[base] && c.precision > 0:
+		return withTypeArgs(c.sqlType, strconv.Itoa(c.precision))
+	}
+	return c.sqlType
+}
+
+// withTypeArgs adds args to sqlType, in front of a "WITH TIME ZONE" suffix if present.
+func withTypeArgs(sqlType string, args string) string {
+	if i := strings.Index(strings.ToUpper(sqlType), " WITH"); i >= 0 {
+		return fmt.Sprintf("%s(%s)%s", sqlType[:i], args, sqlType[i:])
 	}
-	return c.Type()
+	return fmt.Sprintf("%s(%s)", sqlType, args)
+}
+
+// Type returns the column type with its width, precision and scale arguments
+func (c *BaseWidthColumn) Type() string {
+	return c.ColType()
 }
 
 func (c *BaseWidthColumn) GetWidth() int {
 	return c.width
 }
 
+// Precision returns the precision of decimal and time columns
+func (c *BaseWidthColumn) Precision() int {
+	return c.precision
+}
+
+// Scale returns the scale of decimal columns
+func (c *BaseWidthColumn) Scale() int {
+	return c.scale
+}
+
+// stringTypeByWidth returns the type of string columns: VARCHAR(n), CHAR(n) when tagged
+// with fixed, or TEXT when no width is given.
+func stringTypeByWidth(tagmap map[string]string) string {
+	if tagWidth(tagmap) <= 0 {
+		return "TEXT"
+	}
+	if _, ok := tagmap[TAG_FIXED]; ok {
+		return "CHAR"
+	}
+	return "VARCHAR"
+}
+
+// tagWidth returns the width tag.
+func tagWidth(tagmap map[string]string) int {
+	width, _ := strconv.Atoi(tagmap[TAG_WIDTH])
+	return width
+}
+
 func NewBaseWidthColumn(name string, sqltype string, tagmap map[string]string, isPointer bool) BaseWidthColumn {
-	width := 0
-	if v, ok := tagmap[TAG_WIDTH]; ok {
-		width, _ = strconv.Atoi(v)
+	width := tagWidth(tagmap)
+	precision, scale, _ := parsePrecision(tagmap)
+	if v, ok := tagmap[TAG_SCALE]; ok {
+		scale, _ = strconv.Atoi(v)
 	}
 	baseCol := NewBaseColumn(name, sqltype, tagmap, isPointer)
 	return BaseWidthColumn{
 		BaseColumn: baseCol,
 		width:      width,
+		precision:  precision,
+		scale:      scale,
+	}
+}
+
+var typeArgsRegexp = regexp.MustCompile(`^([^(]+?)\s*\(\s*(\d+)\s*(?:,\s*(\d+)\s*)?\)(.*)$`)
+
+// newWidthColumnFromType builds the column of an introspected type, splitting its arguments
+// into width, precision and scale so the column compares equal to one built from tags.
+func newWidthColumnFromType(base BaseColumn, fullType string) BaseWidthColumn {
+	col := BaseWidthColumn{BaseColumn: base}
+	col.sqlType = fullType
+	m := typeArgsRegexp.FindStringSubmatch(fullType)
+	if m == nil {
+		return col
+	}
+	baseType := strings.TrimSpace(m[1]) + m[4]
+	first, _ := strconv.Atoi(m[2])
+	second, _ := strconv.Atoi(m[3])
+	switch upper := strings.ToUpper(baseType); {
+	case widthTypes[upper]:
+		col.width = first
+	case decimalTypes[upper]:
+		col.precision, col.scale = first, second
+	case timeTypes[upper]:
+		col.precision = first
+	default:
+		return col
 	}
+	col.sqlType = baseType
+	return col
 }
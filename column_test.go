@@ -0,0 +1,173 @@
+package aaronsql
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+)
+
+type testWidthModel struct {
+	Name     string    `db:"name:name;width:100"`
+	Code     string    `db:"name:code;width:2;fixed"`
+	Legacy   string    `db:"name:legacy;length:50"`
+	Notes    string    `db:"name:notes"`
+	Price    float64   `db:"name:price;precision:10,2"`
+	Rate     float64   `db:"name:rate;precision:8;scale:4"`
+	Happened time.Time `db:"name:happened;precision:3"`
+}
+
+func TestWidthPrecisionScaleColumns(t *testing.T) {
+	registerTestDB(t, "width_postgres", &PostgresDataBase{DataBase: DataBase{name: PostgresDB}})
+	registerTestDB(t, "width_mariadb", &MariaDBDataBase{DataBase: DataBase{name: MariaDB}})
+
+	expected := map[string]map[string]string{
+		"width_postgres": {
+			"name":     "VARCHAR(100)",
+			"code":     "CHAR(2)",
+			"legacy":   "VARCHAR(50)",
+			"notes":    "TEXT",
+			"price":    "NUMERIC(10,2)",
+			"rate":     "NUMERIC(8,4)",
+			"happened": "TIMESTAMP(3) WITH TIME ZONE",
+		},
+		"width_mariadb": {
+			"name":     "VARCHAR(100)",
+			"code":     "CHAR(2)",
+			"legacy":   "VARCHAR(50)",
+			"notes":    "TEXT",
+			"price":    "DECIMAL(10,2)",
+			"rate":     "DECIMAL(8,4)",
+			"happened": "DATETIME(3)",
+		},
+	}
+	for dbName, columns := range expected {
+		table, err := NewTableFromStructWithDB(testWidthModel{}, "widths", dbName)
+		if err != nil {
+			t.Fatalf("Failed to create table from struct: %v", err)
+		}
+		for name, sqlType := range columns {
+			if got := table.Column(name).Type(); got != sqlType {
+				t.Errorf("Expected column %s on %s to be %s, got %s", name, dbName, sqlType, got)
+			}
+		}
+		if got := table.Column("name").GetWidth(); got != 100 {
+			t.Errorf("Expected width 100 on %s, got %d", dbName, got)
+		}
+	}
+}
+
+func TestIntrospectedWidthColumns(t *testing.T) {
+	cases := map[string]string{
+		"varchar(100)":     "VARCHAR(100)",
+		"decimal(10,2)":    "DECIMAL(10,2)",
+		"datetime(3)":      "DATETIME(3)",
+		"bigint(20)":       "BIGINT",
+		"int(10) unsigned": "INT UNSIGNED",
+		"tinyint(1)":       "BOOLEAN",
+		"enum('On','off')": "ENUM('On','off')",
+		"longtext":         "LONGTEXT",
+		"char(2)":          "CHAR(2)",
+	}
+	for columnType, expected := range cases {
+		col := newWidthColumnFromType(BaseColumn{}, mariadbColumnType(columnType, false))
+		if got := col.Type(); got != expected {
+			t.Errorf("Expected %s to be introspected as %s, got %s", columnType, expected, got)
+		}
+	}
+
+	col := newWidthColumnFromType(BaseColumn{}, "TIMESTAMP(3) WITH TIME ZONE")
+	if col.Precision() != 3 || col.Type() != "TIMESTAMP(3) WITH TIME ZONE" {
+		t.Errorf("Unexpected introspected time column: %s (precision %d)", col.Type(), col.Precision())
+	}
+	col = newWidthColumnFromType(BaseColumn{}, "NUMERIC(12,2)")
+	if col.Precision() != 12 || col.Scale() != 2 {
+		t.Errorf("Unexpected introspected numeric column: precision %d, scale %d", col.Precision(), col.Scale())
+	}
+}
+
+func TestDefaultTimePrecision(t *testing.T) {
+	type event struct {
+		Happened time.Time `db:"name:happened;precision:6"`
+	}
+	registerTestDB(t, "time_precision_postgres", &PostgresDataBase{DataBase: DataBase{name: PostgresDB}})
+	table, err := NewTableFromStructWithDB(event{}, "events", "time_precision_postgres")
+	if err != nil {
+		t.Fatalf("Failed to create table from struct: %v", err)
+	}
+	// PostgreSQL reports the default precision 6 whether it was declared or not
+	introspected := postgresColumnType("timestamptz", sql.NullInt64{}, sql.NullInt64{}, sql.NullInt64{}, sql.NullInt64{Int64: 6, Valid: true})
+	if got := table.Column("happened").Type(); !sameSQLType(got, introspected) {
+		t.Errorf("Expected precision 6 to compare equal to the introspected %s, got %s", introspected, got)
+	}
+}
+
+func TestSameDefault(t *testing.T) {
+	cases := []struct {
+		existing string
+		declared string
+		same     bool
+	}{
+		{"'-1'::integer", "-1", true},
+		{"'guest'::character varying", "'guest'", true},
+		{"'{}'::text[]", "'{}'", true},
+		{"NULL", "", true},
+		{"true", "1", true},
+		{"CURRENT_TIMESTAMP", "current_timestamp", true},
+		{"0", "-1", false},
+		{"'Active'::text", "'active'", false},
+		{"''::text", "", false},
+	}
+	for _, c := range cases {
+		if got := sameDefault(c.existing, c.declared); got != c.same {
+			t.Errorf("Expected sameDefault(%q, %q) to be %v", c.existing, c.declared, c.same)
+		}
+	}
+}
+
+func TestIntrospectedMariaDBTypes(t *testing.T) {
+	cases := []struct {
+		columnType string
+		isJSON     bool
+		expected   string
+	}{
+		{"longtext", true, "JSON"},
+		{"longtext", false, "LONGTEXT"},
+		{"json", false, "JSON"},
+		{"text", true, "TEXT"},
+		{"int(11)", false, "INT"},
+		{"tinyint(1) unsigned", false, "TINYINT UNSIGNED"},
+	}
+	for _, c := range cases {
+		if got := mariadbColumnType(c.columnType, c.isJSON); got != c.expected {
+			t.Errorf("Expected %s (json %v) to be introspected as %s, got %s", c.columnType, c.isJSON, c.expected, got)
+		}
+	}
+}
+
+func TestNullableDefaults(t *testing.T) {
+	type profile struct {
+		ID       int64   `db:"name:id;primary"`
+		Nickname *string `db:"name:nickname"`
+		Bio      *string `db:"name:bio;nullable"`
+		Age      int     `db:"name:age"`
+	}
+	registerTestDB(t, "nullable_postgres", &PostgresDataBase{DataBase: DataBase{name: PostgresDB}})
+	registerTestDB(t, "nullable_mariadb", &MariaDBDataBase{DataBase: DataBase{name: MariaDB}})
+
+	// pointer fields are NOT NULL on PostgreSQL unless tagged nullable, as they always were
+	expected := map[string]map[string]bool{
+		"nullable_postgres": {"id": false, "nickname": false, "bio": true, "age": false},
+		"nullable_mariadb":  {"id": false, "nickname": true, "bio": true, "age": false},
+	}
+	for dbName, columns := range expected {
+		table, err := NewTableFromStructWithDB(profile{}, "profiles", dbName)
+		if err != nil {
+			t.Fatalf("Failed to create table from struct: %v", err)
+		}
+		for name, nullable := range columns {
+			if got := table.Column(name).Nullable(); got != nullable {
+				t.Errorf("Expected column %s on %s to be nullable %v, got %v", name, dbName, nullable, got)
+			}
+		}
+	}
+}
@@ -1,8 +1,12 @@
 package aaronsql
 
 import (
+	"context"
 	"database/sql"
+	"fmt"
 	"reflect"
+	"strings"
+	"time"
 )
 
 type DBName string
@@ -17,37 +21,199 @@ type DBInterface interface {
 	Name() DBName
 	// GetDB returns the underlying sql.DB instance.
 	GetDB() *DataBase
-	// GetTables returns the DDL information for all tables in the database.
-	GetTables() ([]Table, error)
-	GetTableDDL(tableName string) (*Table, error)
+	// GetTables returns the DDL information for all tables in the schema, an empty schema is
+	// the default schema of the connection.
+	GetTables(schema string) ([]Table, error)
+	GetTableDDL(schema, tableName string) (*Table, error)
 
-	GetCreateTableSQL(tableName string, columns []ColumnInterface) string
+	GetCreateTableSQL(schema, tableName string, columns []ColumnInterface, options CreateTableOptions) string
+	// CollationSql returns the character set and collation clauses following the type of a
+	// column, empty when both are empty. PostgreSQL only supports collations.
+	CollationSql(charset, collation string) string
+	// ColumnDefinitionSql returns the definition of col in schema following its name in CREATE
+	// TABLE and ALTER TABLE statements, comments excluded.
+	ColumnDefinitionSql(schema string, col ColumnInterface) string
+	// CreateSchemaSql returns the statement creating the schema if it does not exist. MariaDB
+	// has no schemas within a database, a schema is a database there.
+	CreateSchemaSql(schema string) string
+
+	// MaxIdentifierLength returns the maximum length in bytes of identifiers, longer generated
+	// names are shortened, see NamingStrategy.
+	MaxIdentifierLength() int
+
+	// QuoteIdentifier quotes the parts of a possibly qualified identifier, eg: a schema and
+	// table name, and joins them with dots. Empty parts are skipped.
+	QuoteIdentifier(parts ...string) string
 
 	IsSupportForeignKeys() bool
+	// AutoIncrementTypeSql returns the type of the auto-increment column col in column
+	// definitions, with the generator of its values where the dialect declares it in the type.
+	AutoIncrementTypeSql(col ColumnInterface) string
+	// IsSupportEnumTypes reports whether enums are named types created with CREATE TYPE, MariaDB
+	// declares the values in the column type instead.
+	IsSupportEnumTypes() bool
+	// GetEnumTypes returns the values of the enum types in schema by type name.
+	GetEnumTypes(schema string) (map[string][]string, error)
+	// IsSupportIndexOptions reports whether indexes may use expressions, NULLS ordering, INCLUDE
+	// columns and WHERE predicates.
+	IsSupportIndexOptions() bool
+	// IndexMethod returns the dialect's spelling of the index method, eg: gin on PostgreSQL or
+	// FULLTEXT on MariaDB, or an error if the method is not supported. An empty method is the
+	// default B-tree.
+	IndexMethod(method string) (string, error)
+	// SearchVectorSql returns the expression of the generated column holding the text search
+	// document of the quoted columns, empty when searchable columns are indexed directly.
+	SearchVectorSql(columns []string, config string) string
 	GetTablesColumns(t TableInterface) ([]ColumnInterface, error)
 	GetColumnDefinitionByType(fieldType reflect.Type, columnName string, tag map[string]string, isPointer bool) (ColumnInterface, error)
 
-	DropTableSql(tableName string) string
+	DropTableSql(schema, tableName string) string
 
 	CanInsert() bool
 	CanInsertOrUpdate() bool
 	CanUpdate() bool
 	CanReturnRowsAffected() bool
+	// CanInsertReturning reports whether inserts read generated keys back with RETURNING,
+	// otherwise they are read with LastInsertId
+	CanInsertReturning() bool
 	CanRenameTable() bool
 
 	InsertSqlTemplate() string
 	UpdateSqlTemplate() string
+	SelectSqlTemplate() string
+	// QuerySqlTemplate selects the records of a query, SearchSqlTemplate renders the condition
+	// of a full-text search and SearchRankSqlTemplate its relevance.
+	QuerySqlTemplate() string
+	SearchSqlTemplate() string
+	SearchRankSqlTemplate() string
 
 	CreateIndexSqlTemplate() string
 	DropIndexSqlTemplate() string
 
 	CreateColumnSqlTemplate() string
 	UpdateColumnSqlTemplate() string
+	DropColumnSqlTemplate() string
+	RenameColumnSqlTemplate() string
+
+	AddForeignKeySqlTemplate() string
+	AddCheckSqlTemplate() string
+	DropCheckSqlTemplate() string
+
+	CreateEnumSqlTemplate() string
+	AddEnumValueSqlTemplate() string
+
+	AddAutoIncrementSqlTemplate() string
+
+	TableCommentSqlTemplate() string
+	ColumnCommentSqlTemplate() string
+	TableCharsetSqlTemplate() string
+
+	// AcquireLock takes the database wide lock key on a dedicated connection, waiting at most
+	// timeout. The returned function releases the lock.
+	AcquireLock(ctx context.Context, key string, timeout time.Duration) (func() error, error)
+}
+
+// CreateTableOptions are the table level parts of CREATE TABLE statements.
+type CreateTableOptions struct {
+	// Checks are the CHECK constraints of the table
+	Checks []TableCheck
+	// Comment is the comment of the table, empty for none
+	Comment string
+	// Charset and Collation are the defaults of the columns of the table, MariaDB only
+	Charset   string
+	Collation string
+}
+
+// checkConstraintsSQL renders the CHECK constraints of a CREATE TABLE statement, each one
+// preceded by a comma.
+func checkConstraintsSQL(db DBInterface, checks []TableCheck) string {
+	var sb strings.Builder
+	for _, check := range checks {
+		fmt.Fprintf(&sb, ", CONSTRAINT %s CHECK (%s)", db.QuoteIdentifier(check.name), check.expression)
+	}
+	return sb.String()
 }
 
 type DataBase struct {
 	name DBName
 	db   *sql.DB
+
+	// schema is the default schema of tables which do not set one, empty is the default
+	// schema of the connection: the search path on PostgreSQL, the database on MariaDB.
+	schema string
+	// tenantResolver maps contexts to tenant schemas, see SetTenantResolver
+	tenantResolver TenantResolver
+
+	// templates overrides the SQL templates of the dialect, see SetTemplate
+	templates map[SQLTemplateName]string
+	// namingStrategy names indexes and constraints, see SetNamingStrategy
+	namingStrategy NamingStrategy
+}
+
+// SetSchema sets the default schema of the tables of this database.
+func (d *DataBase) SetSchema(schema string) {
+	d.schema = schema
+}
+
+// Schema returns the default schema of the tables of this database.
+func (d *DataBase) Schema() string {
+	return d.schema
 }
 
 var globalDBInstances = make(map[string]DBInterface)
+
+// columnType returns the type of col in schema followed by its character set and collation,
+// enum types are qualified with the schema on databases where they are named types.
+func columnType(db DBInterface, schema string, col ColumnInterface) string {
+	sqlType := col.Type()
+	if len(col.EnumValues()) > 0 && db.IsSupportEnumTypes() {
+		sqlType = db.QuoteIdentifier(schema, col.Type())
+	}
+	return sqlType + db.CollationSql(col.Charset(), col.Collation())
+}
+
+// columnTypeSQL returns the type of col in column definitions, followed by the generation
+// clause of stored generated columns or the generator of auto-increment columns.
+func columnTypeSQL(db DBInterface, schema string, col ColumnInterface) string {
+	if expression := col.GeneratedExpression(); expression != "" {
+		return fmt.Sprintf("%s GENERATED ALWAYS AS (%s) STORED", columnType(db, schema, col), expression)
+	}
+	if col.IsAutoIncrement() {
+		return db.AutoIncrementTypeSql(col)
+	}
+	return columnType(db, schema, col)
+}
+
+// quoteLiteral quotes s as a SQL string literal, quotes inside s are doubled.
+func quoteLiteral(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+// commentLiteral quotes comment as a SQL string literal, empty comments stay empty.
+func commentLiteral(comment string) string {
+	if comment == "" {
+		return ""
+	}
+	return quoteLiteral(comment)
+}
+
+// quoteIdentifier wraps every non-empty part in quote, quote characters inside a part are doubled.
+func quoteIdentifier(quote string, parts ...string) string {
+	quoted := make([]string, 0, len(parts))
+	for _, part := range parts {
+		if part == "" {
+			continue
+		}
+		quoted = append(quoted, quote+strings.ReplaceAll(part, quote, quote+quote)+quote)
+	}
+	return strings.Join(quoted, ".")
+}
+
+// quoteIdentifiers quotes each of names with the identifier quoting of db.
+func quoteIdentifiers(db DBInterface, names []string) []string {
+	quoted := make([]string, len(names))
+	for i, name := range names {
+		quoted[i] = db.QuoteIdentifier(name)
+	}
+	return quoted
+}
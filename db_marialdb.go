@@ -1,8 +1,11 @@
 package aaronsql
 
 import (
+	"context"
+	"database/sql"
 	"fmt"
 	"reflect"
+	"strings"
 	"time"
 )
 
@@ -11,7 +14,7 @@ type MariaDBDataBase struct {
 }
 
 type MariaDBColumn struct {
-	BaseColumn
+	BaseWidthColumn
 }
 
 // Name returns the name of the database type.
@@ -24,50 +27,38 @@ func (mariadb *MariaDBDataBase) GetDB() *DataBase {
 	return &mariadb.DataBase
 }
 
-// GetTables returns the DDL information for all tables in the database.
-func (mariadb *MariaDBDataBase) GetTables() ([]Table, error) {
-	ret := make([]Table, 0)
-	tableNames, err := mariadb.getTableNames()
+// GetTables returns the DDL information for all tables in the schema, a schema is a database on MariaDB.
+func (mariadb *MariaDBDataBase) GetTables(schema string) ([]Table, error) {
+	tables, err := mariadb.introspect(schema, "")
 	if err != nil {
-		return nil, fmt.Errorf("failed to get table names: %w", err)
+		return nil, fmt.Errorf("failed to get tables: %w", err)
 	}
-	for _, tableName := range tableNames {
-		columns, err := mariadb.getColumnInfo(tableName)
-		if err != nil {
-			return nil, fmt.Errorf("failed to get columns for table %s: %w", tableName, err)
-		}
-		table := Table{
-			name:         tableName,
-			columns:      columns,
-			db:           mariadb,
-			extraOptions: make(map[string]string),
-		}
-		ret = append(ret, table)
+	ret := make([]Table, 0, len(tables))
+	for _, table := range tables {
+		ret = append(ret, *table)
 	}
 	return ret, nil
 }
 
-func (mariadb *MariaDBDataBase) GetCreateTableSQL(tableName string, columns []ColumnInterface) string {
-	sql := fmt.Sprintf("CREATE TABLE `%s` (", tableName)
+// defaultMariaDBCharset and defaultMariaDBCollation are the defaults of tables which set neither
+// EXTRA_CHARSET nor EXTRA_COLLATE.
+const (
+	defaultMariaDBCharset   = "utf8mb4"
+	defaultMariaDBCollation = "utf8mb4_unicode_ci"
+)
+
+func (mariadb *MariaDBDataBase) GetCreateTableSQL(schema, tableName string, columns []ColumnInterface, options CreateTableOptions) string {
+	sql := fmt.Sprintf("CREATE TABLE %s (", mariadb.QuoteIdentifier(schema, tableName))
 	primaryKeys := make([]string, 0)
 
 	for i, col := range columns {
-		sql += fmt.Sprintf("`%s` %s", col.Name(), col.Type())
-
-		if !col.Nullable() {
-			sql += " NOT NULL"
-		}
-
-		if col.Default() != "" {
-			sql += fmt.Sprintf(" DEFAULT %s", col.Default())
-		}
-
-		if col.IsAutoIncrement() {
-			sql += " AUTO_INCREMENT"
+		sql += fmt.Sprintf("%s %s", mariadb.QuoteIdentifier(col.Name()), mariadb.ColumnDefinitionSql(schema, col))
+		if col.Comment() != "" {
+			sql += " COMMENT " + quoteLiteral(col.Comment())
 		}
 
 		if col.IsPrimaryKey() {
-			primaryKeys = append(primaryKeys, fmt.Sprintf("`%s`", col.Name()))
+			primaryKeys = append(primaryKeys, mariadb.QuoteIdentifier(col.Name()))
 		}
 
 		if i < len(columns)-1 {
@@ -82,15 +73,114 @@ func (mariadb *MariaDBDataBase) GetCreateTableSQL(tableName string, columns []Co
 		}
 		sql += ")"
 	}
+	sql += checkConstraintsSQL(mariadb, options.Checks)
+
+	sql += ") ENGINE=InnoDB"
+	charset, collation := options.Charset, options.Collation
+	if charset == "" && collation == "" {
+		charset, collation = defaultMariaDBCharset, defaultMariaDBCollation
+	}
+	if charset != "" {
+		sql += " DEFAULT CHARSET=" + charset
+	}
+	if collation != "" {
+		sql += " COLLATE=" + collation
+	}
+	for _, col := range columns {
+		if col.IsAutoIncrement() && col.AutoIncrementOffset() > 0 {
+			sql += fmt.Sprintf(" AUTO_INCREMENT=%d", col.AutoIncrementOffset())
+			break
+		}
+	}
+	if options.Comment != "" {
+		sql += " COMMENT=" + quoteLiteral(options.Comment)
+	}
+	return sql + ";"
+}
 
-	sql += ") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;"
+// CollationSql returns the CHARACTER SET and COLLATE clauses of a column.
+func (mariadb *MariaDBDataBase) CollationSql(charset, collation string) string {
+	sql := ""
+	if charset != "" {
+		sql += " CHARACTER SET " + charset
+	}
+	if collation != "" {
+		sql += " COLLATE " + collation
+	}
+	return sql
+}
+
+// ColumnDefinitionSql returns the type of col with its NOT NULL and DEFAULT constraints and its
+// AUTO_INCREMENT attribute.
+func (mariadb *MariaDBDataBase) ColumnDefinitionSql(schema string, col ColumnInterface) string {
+	sql := columnTypeSQL(mariadb, schema, col)
+	if !col.Nullable() {
+		sql += " NOT NULL"
+	}
+	if col.Default() != "" {
+		sql += fmt.Sprintf(" DEFAULT %s", col.Default())
+	}
+	if col.IsAutoIncrement() {
+		sql += " AUTO_INCREMENT"
+	}
 	return sql
 }
 
+// QuoteIdentifier quotes identifiers with backticks.
+func (mariadb *MariaDBDataBase) QuoteIdentifier(parts ...string) string {
+	return quoteIdentifier("`", parts...)
+}
+
+func (mariadb *MariaDBDataBase) CreateSchemaSql(schema string) string {
+	return fmt.Sprintf("CREATE DATABASE IF NOT EXISTS %s;", mariadb.QuoteIdentifier(schema))
+}
+
 func (mariadb *MariaDBDataBase) IsSupportForeignKeys() bool {
 	return true
 }
 
+// SearchVectorSql is empty, MariaDB searches the columns of a FULLTEXT index.
+func (mariadb *MariaDBDataBase) SearchVectorSql(columns []string, config string) string {
+	return ""
+}
+
+func (mariadb *MariaDBDataBase) MaxIdentifierLength() int {
+	return 64
+}
+
+// AutoIncrementTypeSql returns the type of col, AUTO_INCREMENT is a column attribute added by
+// GetCreateTableSQL.
+func (mariadb *MariaDBDataBase) AutoIncrementTypeSql(col ColumnInterface) string {
+	return col.Type()
+}
+
+// IsSupportEnumTypes is false, enum values are part of the ENUM column type.
+func (mariadb *MariaDBDataBase) IsSupportEnumTypes() bool {
+	return false
+}
+
+// GetEnumTypes returns no types, MariaDB has no named enum types.
+func (mariadb *MariaDBDataBase) GetEnumTypes(schema string) (map[string][]string, error) {
+	return nil, nil
+}
+
+// IsSupportIndexOptions is false, MariaDB indexes only support descending key parts.
+func (mariadb *MariaDBDataBase) IsSupportIndexOptions() bool {
+	return false
+}
+
+// IndexMethod accepts FULLTEXT and SPATIAL indexes and the HASH and BTREE algorithms, they are
+// upper case. InnoDB builds B-trees for HASH indexes, use it with engines supporting them.
+func (mariadb *MariaDBDataBase) IndexMethod(method string) (string, error) {
+	switch method = strings.ToUpper(method); method {
+	case "", "BTREE":
+		return "BTREE", nil
+	case "HASH", "FULLTEXT", "SPATIAL":
+		return method, nil
+	}
+	return "", fmt.Errorf("unsupported index type %q for database: %s", method, mariadb.Name())
+}
+
 func (mariadb *MariaDBDataBase) GetTablesColumns(t TableInterface) ([]ColumnInterface, error) {
 	ret := make([]ColumnInterface, 0)
 	for _, col := range t.Columns() {
@@ -105,123 +195,122 @@ func (mariadb *MariaDBDataBase) GetTablesColumns(t TableInterface) ([]ColumnInte
 }
 
 func (mariadb *MariaDBDataBase) GetColumnDefinitionByType(fieldType reflect.Type, columnName string, tag map[string]string, isPointer bool) (ColumnInterface, error) {
-	retCol := MariaDBColumn{}
-	retCol.name = columnName
-	retCol.isPointer = isPointer
-
 	// Handle pointer types by getting the underlying type
 	actualType := fieldType
 	if fieldType.Kind() == reflect.Ptr {
 		actualType = fieldType.Elem()
-		retCol.isPointer = true
+		isPointer = true
 	}
 
-	// Set default nullable based on pointer type
-	retCol.isNullable = isPointer
+	var enum []string
+	sqlType, converter, ok := resolveCustomType(mariadb.Name(), actualType, tag)
+	if !ok {
+		if enum, ok = enumValues(actualType); ok {
+			sqlType = "ENUM(" + enumValuesSQL(enum) + ")"
+		}
+	}
+	isJSON := isJSONType(actualType, tag)
+	if isJSON && converter == nil {
+		converter = jsonValueConverter
+	}
+	if !ok && isJSON {
+		sqlType = "JSON"
+	} else if !ok {
+		var err error
+		if sqlType, err = mariadb.sqlTypeByKind(actualType, tag); err != nil {
+			return nil, err
+		}
+	}
+	retCol := MariaDBColumn{BaseWidthColumn: NewBaseWidthColumn(columnName, sqlType, tag, isPointer)}
+	retCol.converter = converter
+	retCol.parser = textParserFor(actualType)
+	retCol.isJSON = isJSON
+	retCol.enumValues = enum
+	return &retCol, nil
+}
 
+// sqlTypeByKind maps the kind of a field type to the MariaDB column type.
+func (mariadb *MariaDBDataBase) sqlTypeByKind(actualType reflect.Type, tag map[string]string) (string, error) {
 	switch actualType.Kind() {
 	case reflect.String:
-		if length, ok := tag["length"]; ok {
-			retCol.sqlType = fmt.Sprintf("VARCHAR(%s)", length)
-		} else {
-			retCol.sqlType = "TEXT"
-		}
+		return stringTypeByWidth(tag), nil
 	case reflect.Int8:
-		retCol.sqlType = "TINYINT"
+		return "TINYINT", nil
 	case reflect.Int16:
-		retCol.sqlType = "SMALLINT"
+		return "SMALLINT", nil
 	case reflect.Int32, reflect.Int:
-		retCol.sqlType = "INT"
+		return "INT", nil
 	case reflect.Int64:
-		retCol.sqlType = "BIGINT"
+		return "BIGINT", nil
 	case reflect.Uint8:
-		retCol.sqlType = "TINYINT UNSIGNED"
+		return "TINYINT UNSIGNED", nil
 	case reflect.Uint16:
-		retCol.sqlType = "SMALLINT UNSIGNED"
+		return "SMALLINT UNSIGNED", nil
 	case reflect.Uint32, reflect.Uint:
-		retCol.sqlType = "INT UNSIGNED"
+		return "INT UNSIGNED", nil
 	case reflect.Uint64:
-		retCol.sqlType = "BIGINT UNSIGNED"
-	case reflect.Float32:
-		retCol.sqlType = "FLOAT"
-	case reflect.Float64:
-		retCol.sqlType = "DOUBLE"
+		return "BIGINT UNSIGNED", nil
+	case reflect.Float32, reflect.Float64:
+		if _, _, ok := parsePrecision(tag); ok {
+			return "DECIMAL", nil
+		}
+		if actualType.Kind() == reflect.Float32 {
+			return "FLOAT", nil
+		}
+		return "DOUBLE", nil
 	case reflect.Bool:
-		retCol.sqlType = "BOOLEAN"
+		return "BOOLEAN", nil
 	case reflect.Slice:
 		if actualType == reflect.TypeOf([]byte{}) {
-			retCol.sqlType = "LONGBLOB"
-		} else {
-			return nil, fmt.Errorf("unsupported slice type: %s", actualType.String())
+			return "LONGBLOB", nil
 		}
+		return "", fmt.Errorf("unsupported slice type: %s", actualType.String())
 	case reflect.Struct:
 		if actualType == reflect.TypeOf(time.Time{}) {
-			retCol.sqlType = "DATETIME"
-		} else {
-			return nil, fmt.Errorf("unsupported struct type: %s", actualType.Name())
+			return "DATETIME", nil
 		}
+		if actualType == bigFloatType || actualType == bigRatType || actualType == bigIntType {
+			return "DECIMAL", nil
+		}
+		if _, _, ok := parsePrecision(tag); ok && isCustomValueType(actualType) {
+			return "DECIMAL", nil
+		}
+		return "", unsupportedStructTypeError(actualType)
 	default:
-		return nil, fmt.Errorf("unsupported field type: %s", actualType.Kind().String())
-	}
-
-	// Process tags
-	if defaultValue, ok := tag["default"]; ok {
-		retCol.defaultString = defaultValue
-	} else {
-		retCol.defaultString = ""
-	}
-
-	if nullable, ok := tag[TAG_NULLABLE]; ok && (nullable == "" || nullable == "true" || nullable == "1") {
-		retCol.isNullable = true
-	} else if nullable, ok := tag[TAG_NULLABLE]; ok && (nullable == "false" || nullable == "0") {
-		retCol.isNullable = false
-	}
-
-	if primaryKey, ok := tag[TAG_PRIMARY]; ok && (primaryKey == "" || primaryKey == "true" || primaryKey == "1") {
-		retCol.isPrimaryKey = true
-		retCol.isNullable = false // Primary keys cannot be null
-	} else {
-		retCol.isPrimaryKey = false
+		return "", fmt.Errorf("unsupported field type: %s", actualType.Kind().String())
 	}
+}
 
-	if autoIncrement, ok := tag[TAG_AUTO_INCREMENT]; ok && (autoIncrement == "" || autoIncrement == "true" || autoIncrement == "1") {
-		retCol.SetAutoIncrement(true)
-	} else {
-		retCol.SetAutoIncrement(false)
+// mariadbIntegerTypes lists the integer types whose display width is dropped on introspection.
+var mariadbIntegerTypes = map[string]bool{"TINYINT": true, "SMALLINT": true, "MEDIUMINT": true, "INT": true, "BIGINT": true}
+
+// mariadbColumnType converts a COLUMN_TYPE reported by information_schema to the format
+// GetColumnDefinitionByType generates, so Sync can compare both. Type names are upper cased,
+// arguments such as enum values are kept as they are. MariaDB JSON columns are LONGTEXT columns
+// with a json_valid check, isJSON tells they have one.
+func mariadbColumnType(columnType string, isJSON bool) string {
+	name, args, suffix := columnType, "", ""
+	if open := strings.Index(columnType, "("); open >= 0 {
+		if end := strings.LastIndex(columnType, ")"); end > open {
+			name, args, suffix = columnType[:open], columnType[open:end+1], columnType[end+1:]
+		}
 	}
-
-	if unique, ok := tag[TAG_UNIQUE]; ok && (unique == "" || unique == "true" || unique == "1") {
-		retCol.isUnique = true
-	} else {
-		retCol.isUnique = false
+	name = strings.ToUpper(strings.TrimSpace(name))
+	suffix = strings.ToUpper(suffix)
+	if isJSON && name == "LONGTEXT" {
+		return "JSON"
 	}
-
-	if index, ok := tag[TAG_INDEX]; ok && (index == "" || index == "true" || index == "1") {
-		retCol.isIndex = true
-	} else {
-		retCol.isIndex = false
+	if name == "TINYINT" && args == "(1)" && suffix == "" {
+		return "BOOLEAN"
 	}
-
-	if allowZero, ok := tag[TAG_ALLOW_ZERO]; ok && (allowZero == "" || allowZero == "true" || allowZero == "1") {
-		retCol.isAllowZero = true
-	} else {
-		retCol.isAllowZero = false
+	if mariadbIntegerTypes[name] {
+		args = ""
 	}
-
-	retCol.tags = tag
-	retCol.columnIndex = -1 // Default value, can be set later if needed
-
-	if name, ok := tag[TAG_NAME]; ok {
-		retCol.name = name
-	} else {
-		retCol.name = columnName
-	}
-
-	return &retCol, nil
+	return name + args + suffix
 }
 
-func (mariadb *MariaDBDataBase) DropTableSql(tableName string) string {
-	return fmt.Sprintf("DROP TABLE IF EXISTS `%s`;", tableName)
+func (mariadb *MariaDBDataBase) DropTableSql(schema, tableName string) string {
+	return fmt.Sprintf("DROP TABLE IF EXISTS %s;", mariadb.QuoteIdentifier(schema, tableName))
 }
 
 func (mariadb *MariaDBDataBase) CanInsert() bool {
@@ -240,73 +329,213 @@ func (mariadb *MariaDBDataBase) CanReturnRowsAffected() bool {
 	return true
 }
 
+func (mariadb *MariaDBDataBase) CanInsertReturning() bool {
+	return false
+}
+
 func (mariadb *MariaDBDataBase) CanRenameTable() bool {
 	return true
 }
 
 func (mariadb *MariaDBDataBase) InsertSqlTemplate() string {
-	return "INSERT INTO `{{.TableName}}` ({{.Columns}}) VALUES ({{.Values}});"
+	return "INSERT INTO {{.TableName}} ({{.Columns}}) VALUES ({{.Values}});"
 }
 
 func (mariadb *MariaDBDataBase) UpdateSqlTemplate() string {
-	return "UPDATE `{{.TableName}}` SET {{.Updates}} WHERE {{.Conditions}};"
+	return "UPDATE {{.TableName}} SET {{.Updates}} WHERE {{.Conditions}};"
+}
+
+func (mariadb *MariaDBDataBase) SelectSqlTemplate() string {
+	return "SELECT {{.Columns}} FROM {{.TableName}} WHERE {{.Conditions}};"
+}
+
+// QuerySqlTemplate needs a LIMIT for OFFSET, the maximum row count stands in when none is set.
+func (mariadb *MariaDBDataBase) QuerySqlTemplate() string {
+	return "SELECT {{.Columns}} FROM {{.TableName}}{{if .Conditions}} WHERE {{.Conditions}}{{end}}{{if .OrderBy}} ORDER BY {{.OrderBy}}{{end}}{{if .Limit}} LIMIT {{.Limit}}{{else if .Offset}} LIMIT 18446744073709551615{{end}}{{if .Offset}} OFFSET {{.Offset}}{{end}};"
+}
+
+func (mariadb *MariaDBDataBase) SearchSqlTemplate() string {
+	return "MATCH ({{.Columns}}) AGAINST ({{.Values}} IN NATURAL LANGUAGE MODE)"
+}
+
+func (mariadb *MariaDBDataBase) SearchRankSqlTemplate() string {
+	return "MATCH ({{.Columns}}) AGAINST ({{.Values}} IN NATURAL LANGUAGE MODE)"
 }
 
 func (mariadb *MariaDBDataBase) CreateIndexSqlTemplate() string {
-	return "CREATE INDEX `{{.IndexName}}` ON `{{.TableName}}` ({{.Columns}});"
+	return "CREATE {{if .Unique}}UNIQUE {{end}}{{if or (eq .Method \"FULLTEXT\") (eq .Method \"SPATIAL\")}}{{.Method}} {{end}}INDEX {{if .IfNotExists}}IF NOT EXISTS {{end}}{{.IndexName}} ON {{.TableName}} ({{.Columns}}){{if eq .Method \"HASH\"}} USING HASH{{end}}{{if .Concurrently}} ALGORITHM=INPLACE LOCK=NONE{{end}};"
 }
 
 func (mariadb *MariaDBDataBase) DropIndexSqlTemplate() string {
-	return "DROP INDEX `{{.IndexName}}` ON `{{.TableName}}`;"
+	return "DROP INDEX {{.IndexName}} ON {{.TableName}}{{if .Concurrently}} ALGORITHM=INPLACE LOCK=NONE{{end}};"
 }
 
 func (mariadb *MariaDBDataBase) CreateColumnSqlTemplate() string {
-	return "ALTER TABLE `{{.TableName}}` ADD COLUMN `{{.ColumnName}}` {{.ColumnType}};"
+	return "ALTER TABLE {{.TableName}} ADD COLUMN {{if .IfNotExists}}IF NOT EXISTS {{end}}{{.ColumnName}} {{.ColumnType}}{{if .Comment}} COMMENT {{.Comment}}{{end}};"
 }
 
+// UpdateColumnSqlTemplate redefines the whole column, MODIFY drops the attributes and comment
+// it does not repeat.
 func (mariadb *MariaDBDataBase) UpdateColumnSqlTemplate() string {
-	return "ALTER TABLE `{{.TableName}}` MODIFY COLUMN `{{.ColumnName}}` {{.ColumnType}};"
+	return "ALTER TABLE {{.TableName}} MODIFY COLUMN {{.ColumnName}} {{.Definition}}{{if .Comment}} COMMENT {{.Comment}}{{end}};"
+}
+
+func (mariadb *MariaDBDataBase) DropColumnSqlTemplate() string {
+	return "ALTER TABLE {{.TableName}} DROP COLUMN IF EXISTS {{.ColumnName}};"
+}
+
+func (mariadb *MariaDBDataBase) RenameColumnSqlTemplate() string {
+	return "ALTER TABLE {{.TableName}} RENAME COLUMN {{.ColumnName}} TO {{.NewColumnName}};"
+}
+
+func (mariadb *MariaDBDataBase) AddForeignKeySqlTemplate() string {
+	return "ALTER TABLE {{.TableName}} ADD CONSTRAINT {{.ConstraintName}} FOREIGN KEY ({{.Columns}}) REFERENCES {{.ReferencedTable}} ({{.ReferencedColumns}}){{if .OnDelete}} ON DELETE {{.OnDelete}}{{end}};"
+}
+
+func (mariadb *MariaDBDataBase) AddCheckSqlTemplate() string {
+	return "ALTER TABLE {{.TableName}} ADD CONSTRAINT {{.ConstraintName}} CHECK ({{.Expression}});"
+}
+
+func (mariadb *MariaDBDataBase) DropCheckSqlTemplate() string {
+	return "ALTER TABLE {{.TableName}} DROP CONSTRAINT IF EXISTS {{.ConstraintName}};"
 }
 
-func (mariadb *MariaDBDataBase) GetTableDDL(tableName string) (*Table, error) {
-	table := &Table{
-		name:        tableName,
-		columns:     make([]ColumnInterface, 0),
-		indexes:     make([]TableIndex, 0),
-		constraints: make([]TableForeignKey, 0),
-		db:          mariadb,
+// CreateEnumSqlTemplate and AddEnumValueSqlTemplate are empty, MariaDB enums are column types
+// and new values are added by modifying the column.
+func (mariadb *MariaDBDataBase) CreateEnumSqlTemplate() string {
+	return ""
+}
+
+func (mariadb *MariaDBDataBase) AddEnumValueSqlTemplate() string {
+	return ""
+}
+
+func (mariadb *MariaDBDataBase) AddAutoIncrementSqlTemplate() string {
+	return "ALTER TABLE {{.TableName}} MODIFY COLUMN {{.ColumnName}} {{.Definition}}{{if .Comment}} COMMENT {{.Comment}}{{end}};"
+}
+
+func (mariadb *MariaDBDataBase) TableCommentSqlTemplate() string {
+	return "ALTER TABLE {{.TableName}} COMMENT = {{if .Comment}}{{.Comment}}{{else}}''{{end}};"
+}
+
+// ColumnCommentSqlTemplate modifies the column, repeating its whole definition.
+func (mariadb *MariaDBDataBase) ColumnCommentSqlTemplate() string {
+	return "ALTER TABLE {{.TableName}} MODIFY COLUMN {{.ColumnName}} {{.Definition}} COMMENT {{if .Comment}}{{.Comment}}{{else}}''{{end}};"
+}
+
+// TableCharsetSqlTemplate changes the defaults of the columns added later, existing columns keep
+// their character set and collation.
+func (mariadb *MariaDBDataBase) TableCharsetSqlTemplate() string {
+	return "ALTER TABLE {{.TableName}}{{if .Charset}} DEFAULT CHARACTER SET {{.Charset}}{{end}}{{if .Collation}} COLLATE {{.Collation}}{{end}};"
+}
+
+func (mariadb *MariaDBDataBase) GetTableDDL(schema, tableName string) (*Table, error) {
+	tables, err := mariadb.introspect(schema, tableName)
+	if err != nil {
+		return nil, err
+	}
+	if len(tables) == 0 {
+		return nil, nil // Table doesn't exist
+	}
+	return tables[0], nil
+}
+
+// introspect loads the columns, indexes and foreign keys of all tables in schema with a single
+// query each, a non-empty tableName restricts it to that table.
+func (mariadb *MariaDBDataBase) introspect(schema, tableName string) ([]*Table, error) {
+	columns, tableNames, err := mariadb.getColumns(schema, tableName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get columns: %w", err)
+	}
+	indexes, err := mariadb.getIndexes(schema, tableName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get indexes: %w", err)
+	}
+	foreignKeys, err := mariadb.getForeignKeys(schema, tableName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get foreign keys: %w", err)
 	}
+	checks, err := mariadb.getChecks(schema, tableName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get check constraints: %w", err)
+	}
+	options, err := mariadb.getTableOptions(schema, tableName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get table options: %w", err)
+	}
+	tables := make([]*Table, 0, len(tableNames))
+	for _, name := range tableNames {
+		extraOptions := make(map[string]string)
+		if opts, ok := options[name]; ok {
+			extraOptions[EXTRA_CHARSET] = opts.Charset
+			extraOptions[EXTRA_COLLATE] = opts.Collation
+		}
+		tables = append(tables, &Table{
+			schema:       schema,
+			name:         name,
+			columns:      columns[name],
+			indexes:      indexes[name],
+			constraints:  foreignKeys[name],
+			checks:       checks[name],
+			comment:      options[name].Comment,
+			db:           mariadb,
+			extraOptions: extraOptions,
+		})
+	}
+	return tables, nil
+}
 
-	columnMap := make(map[string]*MariaDBColumn)
-	columnQuery := `
+// getColumns returns the columns of the base tables in schema by table name, and the table
+// names in order.
+func (mariadb *MariaDBDataBase) getColumns(schema, tableName string) (map[string][]ColumnInterface, []string, error) {
+	query := `
 		SELECT
-			COLUMN_NAME,
-			DATA_TYPE,
-			IS_NULLABLE,
-			COLUMN_DEFAULT,
-			COLUMN_KEY,
-			EXTRA
+			c.TABLE_NAME,
+			c.COLUMN_NAME,
+			c.COLUMN_TYPE,
+			c.IS_NULLABLE,
+			c.COLUMN_DEFAULT,
+			c.COLUMN_KEY,
+			c.EXTRA,
+			t.AUTO_INCREMENT,
+			c.COLUMN_COMMENT,
+			COALESCE(c.CHARACTER_SET_NAME, ''),
+			COALESCE(c.COLLATION_NAME, ''),
+			EXISTS (
+				SELECT 1 FROM INFORMATION_SCHEMA.CHECK_CONSTRAINTS cc
+				WHERE cc.CONSTRAINT_SCHEMA = c.TABLE_SCHEMA AND cc.TABLE_NAME = c.TABLE_NAME
+					AND cc.CONSTRAINT_NAME = c.COLUMN_NAME AND cc.CHECK_CLAUSE LIKE 'json_valid(%'
+			)
 		FROM
-			INFORMATION_SCHEMA.COLUMNS
+			INFORMATION_SCHEMA.COLUMNS c
+			JOIN INFORMATION_SCHEMA.TABLES t ON t.TABLE_SCHEMA = c.TABLE_SCHEMA AND t.TABLE_NAME = c.TABLE_NAME
 		WHERE
-			TABLE_SCHEMA = DATABASE() AND TABLE_NAME = ?
+			c.TABLE_SCHEMA = COALESCE(NULLIF(?, ''), DATABASE())
+			AND (? = '' OR c.TABLE_NAME = ?)
+			AND t.TABLE_TYPE = 'BASE TABLE'
 		ORDER BY
-			ORDINAL_POSITION;
+			c.TABLE_NAME, c.ORDINAL_POSITION;
 	`
-
-	rows, err := mariadb.db.Query(columnQuery, tableName)
+	rows, err := mariadb.db.Query(query, schema, tableName, tableName)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	defer func() {
 		_ = rows.Close()
 	}()
 
+	columns := make(map[string][]ColumnInterface)
+	var tableNames []string
 	for rows.Next() {
-		var colName, dataType, isNullable, columnKey, extra string
+		var table, colName, columnType, isNullable, columnKey, extra, comment, charset, collation string
 		var defaultValue *string
-		if err := rows.Scan(&colName, &dataType, &isNullable, &defaultValue, &columnKey, &extra); err != nil {
-			return nil, err
+		var autoIncrement sql.NullInt64
+		var isJSON bool
+		if err := rows.Scan(&table, &colName, &columnType, &isNullable, &defaultValue, &columnKey, &extra, &autoIncrement, &comment, &charset, &collation, &isJSON); err != nil {
+			return nil, nil, err
+		}
+		if _, ok := columns[table]; !ok {
+			tableNames = append(tableNames, table)
 		}
 
 		defaultStr := ""
@@ -315,96 +544,125 @@ func (mariadb *MariaDBDataBase) GetTableDDL(tableName string) (*Table, error) {
 		}
 
 		column := &MariaDBColumn{
-			BaseColumn: BaseColumn{
+			BaseWidthColumn: newWidthColumnFromType(BaseColumn{
 				name:          colName,
-				sqlType:       dataType,
 				isNullable:    isNullable == "YES",
 				defaultString: defaultStr,
 				isPrimaryKey:  columnKey == "PRI",
-			},
+				enumValues:    parseEnumValues(columnType),
+				comment:       comment,
+				charset:       charset,
+				collation:     collation,
+			}, mariadbColumnType(columnType, isJSON)),
 		}
-		column.SetAutoIncrement(extra == "auto_increment")
-		columnMap[colName] = column
-	}
-
-	if err := rows.Err(); err != nil {
-		return nil, err
-	}
-
-	if len(columnMap) == 0 {
-		return nil, nil // Table doesn't exist
-	}
-
-	table.columns = make([]ColumnInterface, 0, len(columnMap))
-	for _, col := range columnMap {
-		table.columns = append(table.columns, col)
+		if extra == "auto_increment" {
+			// the counter of the table is the next value of its auto-increment column
+			column.SetAutoIncrement(true)
+			column.SetAutoIncrementOffset(autoIncrement.Int64)
+		}
+		columns[table] = append(columns[table], column)
 	}
+	return columns, tableNames, rows.Err()
+}
 
-	// Query for indexes
-	indexQuery := `
+// getIndexes returns the indexes of the tables in schema by table name, primary keys excluded.
+func (mariadb *MariaDBDataBase) getIndexes(schema, tableName string) (map[string][]TableIndex, error) {
+	query := `
 		SELECT
+			TABLE_NAME,
 			INDEX_NAME,
 			COLUMN_NAME,
-			NON_UNIQUE
+			NON_UNIQUE,
+			COALESCE(COLLATION, 'A'),
+			INDEX_TYPE
 		FROM
 			INFORMATION_SCHEMA.STATISTICS
 		WHERE
-			TABLE_SCHEMA = DATABASE() AND TABLE_NAME = ?
+			TABLE_SCHEMA = COALESCE(NULLIF(?, ''), DATABASE())
+			AND (? = '' OR TABLE_NAME = ?)
 			AND INDEX_NAME != 'PRIMARY'
 		ORDER BY
-			INDEX_NAME, SEQ_IN_INDEX;
+			TABLE_NAME, INDEX_NAME, SEQ_IN_INDEX;
 	`
-
-	indexRows, err := mariadb.db.Query(indexQuery, tableName)
+	rows, err := mariadb.db.Query(query, schema, tableName, tableName)
 	if err != nil {
 		return nil, err
 	}
 	defer func() {
-		_ = indexRows.Close()
+		_ = rows.Close()
 	}()
 
-	indexMap := make(map[string][]string)
-	uniqueMap := make(map[string]bool)
-
-	for indexRows.Next() {
-		var indexName, columnName string
+	indexes := make(map[string][]TableIndex)
+	for rows.Next() {
+		var table, indexName, columnName, collation, method string
 		var nonUnique int
-		if err := indexRows.Scan(&indexName, &columnName, &nonUnique); err != nil {
+		if err := rows.Scan(&table, &indexName, &columnName, &nonUnique, &collation, &method); err != nil {
 			return nil, err
 		}
-
-		indexMap[indexName] = append(indexMap[indexName], columnName)
-		if nonUnique == 0 { // 0 means unique, 1 means non-unique
-			uniqueMap[indexName] = true
+		// COLLATION is D for descending key parts
+		column := IndexColumn{Column: columnName, Desc: collation == "D"}
+		tableIndexes := indexes[table]
+		if n := len(tableIndexes); n > 0 && tableIndexes[n-1].name == indexName {
+			tableIndexes[n-1].columns = append(tableIndexes[n-1].columns, column)
+			continue
 		}
+		indexes[table] = append(tableIndexes, TableIndex{
+			name:     indexName,
+			columns:  []IndexColumn{column},
+			method:   method,
+			isUnique: nonUnique == 0, // 0 means unique, 1 means non-unique
+		})
 	}
+	return indexes, rows.Err()
+}
 
-	if err := indexRows.Err(); err != nil {
+// getTableOptions returns the comments and the default character sets and collations of the
+// tables in schema by table name.
+func (mariadb *MariaDBDataBase) getTableOptions(schema, tableName string) (map[string]CreateTableOptions, error) {
+	// the character set of a collation is its prefix, eg: utf8mb4 for utf8mb4_bin
+	query := `
+		SELECT
+			TABLE_NAME,
+			TABLE_COMMENT,
+			COALESCE(SUBSTRING_INDEX(TABLE_COLLATION, '_', 1), ''),
+			COALESCE(TABLE_COLLATION, '')
+		FROM INFORMATION_SCHEMA.TABLES
+		WHERE
+			TABLE_SCHEMA = COALESCE(NULLIF(?, ''), DATABASE())
+			AND (? = '' OR TABLE_NAME = ?)
+			AND TABLE_TYPE = 'BASE TABLE';
+	`
+	rows, err := mariadb.db.Query(query, schema, tableName, tableName)
+	if err != nil {
 		return nil, err
 	}
+	defer func() {
+		_ = rows.Close()
+	}()
 
-	// Create TableIndex objects
-	for indexName, columns := range indexMap {
-		isUnique := uniqueMap[indexName]
-		index := TableIndex{
-			name:     indexName,
-			columns:  columns,
-			isUnique: isUnique,
+	options := make(map[string]CreateTableOptions)
+	for rows.Next() {
+		var table string
+		var opts CreateTableOptions
+		if err := rows.Scan(&table, &opts.Comment, &opts.Charset, &opts.Collation); err != nil {
+			return nil, err
 		}
-		table.indexes = append(table.indexes, index)
+		options[table] = opts
 	}
-
-	return table, nil
+	return options, rows.Err()
 }
 
-func (mariadb *MariaDBDataBase) getTableNames() ([]string, error) {
+// getChecks returns the CHECK constraints of the tables in schema by table name.
+func (mariadb *MariaDBDataBase) getChecks(schema, tableName string) (map[string][]TableCheck, error) {
 	query := `
-		SELECT TABLE_NAME
-		FROM INFORMATION_SCHEMA.TABLES
-		WHERE TABLE_SCHEMA = DATABASE() AND TABLE_TYPE = 'BASE TABLE';
+		SELECT TABLE_NAME, CONSTRAINT_NAME, CHECK_CLAUSE
+		FROM INFORMATION_SCHEMA.CHECK_CONSTRAINTS
+		WHERE
+			CONSTRAINT_SCHEMA = COALESCE(NULLIF(?, ''), DATABASE())
+			AND (? = '' OR TABLE_NAME = ?)
+		ORDER BY TABLE_NAME, CONSTRAINT_NAME;
 	`
-
-	rows, err := mariadb.db.Query(query)
+	rows, err := mariadb.db.Query(query, schema, tableName, tableName)
 	if err != nil {
 		return nil, err
 	}
@@ -412,26 +670,30 @@ func (mariadb *MariaDBDataBase) getTableNames() ([]string, error) {
 		_ = rows.Close()
 	}()
 
-	var tables []string
+	checks := make(map[string][]TableCheck)
 	for rows.Next() {
-		var tableName string
-		if err := rows.Scan(&tableName); err != nil {
+		var table, name, clause string
+		if err := rows.Scan(&table, &name, &clause); err != nil {
 			return nil, err
 		}
-		tables = append(tables, tableName)
+		checks[table] = append(checks[table], TableCheck{name: name, expression: clause})
 	}
-	return tables, nil
+	return checks, rows.Err()
 }
 
-func (mariadb *MariaDBDataBase) getColumnInfo(tableName string) ([]ColumnInterface, error) {
+// getForeignKeys returns the foreign key constraints of the tables in schema by table name,
+// only their names are loaded.
+func (mariadb *MariaDBDataBase) getForeignKeys(schema, tableName string) (map[string][]TableForeignKey, error) {
 	query := `
-		SELECT COLUMN_NAME, DATA_TYPE, IS_NULLABLE, COLUMN_DEFAULT, COLUMN_KEY, EXTRA
-		FROM INFORMATION_SCHEMA.COLUMNS
-		WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = ?
-		ORDER BY ORDINAL_POSITION;
+		SELECT TABLE_NAME, CONSTRAINT_NAME
+		FROM INFORMATION_SCHEMA.TABLE_CONSTRAINTS
+		WHERE
+			TABLE_SCHEMA = COALESCE(NULLIF(?, ''), DATABASE())
+			AND (? = '' OR TABLE_NAME = ?)
+			AND CONSTRAINT_TYPE = 'FOREIGN KEY'
+		ORDER BY TABLE_NAME, CONSTRAINT_NAME;
 	`
-
-	rows, err := mariadb.db.Query(query, tableName)
+	rows, err := mariadb.db.Query(query, schema, tableName, tableName)
 	if err != nil {
 		return nil, err
 	}
@@ -439,31 +701,46 @@ func (mariadb *MariaDBDataBase) getColumnInfo(tableName string) ([]ColumnInterfa
 		_ = rows.Close()
 	}()
 
-	var columns []ColumnInterface
+	foreignKeys := make(map[string][]TableForeignKey)
 	for rows.Next() {
-		var colName, dataType, isNullable, columnKey, extra string
-		var defaultValue *string
-		if err := rows.Scan(&colName, &dataType, &isNullable, &defaultValue, &columnKey, &extra); err != nil {
+		var table, name string
+		if err := rows.Scan(&table, &name); err != nil {
 			return nil, err
 		}
+		foreignKeys[table] = append(foreignKeys[table], TableForeignKey{name: name})
+	}
+	return foreignKeys, rows.Err()
+}
 
-		defaultStr := ""
-		if defaultValue != nil {
-			defaultStr = *defaultValue
-		}
-
-		column := &MariaDBColumn{
-			BaseColumn: BaseColumn{
-				name:          colName,
-				sqlType:       dataType,
-				isNullable:    isNullable == "YES",
-				defaultString: defaultStr,
-				isPrimaryKey:  columnKey == "PRI",
-			},
+// AcquireLock takes a named lock with GET_LOCK on a dedicated connection.
+func (mariadb *MariaDBDataBase) AcquireLock(ctx context.Context, key string, timeout time.Duration) (func() error, error) {
+	conn, err := mariadb.db.Conn(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get connection for lock %s: %w", key, err)
+	}
+	name := lockName(key)
+	// a negative timeout waits forever, the context still ends the wait
+	seconds := -1.0
+	if timeout > 0 {
+		seconds = timeout.Seconds()
+	}
+	var acquired sql.NullInt64
+	if err := conn.QueryRowContext(ctx, "SELECT GET_LOCK(?, ?)", name, seconds).Scan(&acquired); err != nil {
+		discardConn(conn)
+		return nil, fmt.Errorf("failed to acquire lock %s: %w", key, err)
+	}
+	if !acquired.Valid || acquired.Int64 != 1 {
+		_ = conn.Close()
+		if acquired.Valid {
+			return nil, fmt.Errorf("%w: %s", ErrLockTimeout, key)
 		}
-		column.SetAutoIncrement(extra == "auto_increment")
-		columns = append(columns, column)
+		return nil, fmt.Errorf("failed to acquire lock %s", key)
 	}
-
-	return columns, nil
+	return func() error {
+		if _, err := conn.ExecContext(context.Background(), "SELECT RELEASE_LOCK(?)", name); err != nil {
+			discardConn(conn)
+			return fmt.Errorf("failed to release lock %s: %w", key, err)
+		}
+		return conn.Close()
+	}, nil
 }
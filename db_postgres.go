@@ -1,10 +1,16 @@
 package aaronsql
 
 import (
+	"context"
+	"database/sql"
+	"errors"
 	"fmt"
+	"net"
 	"reflect"
 	"strings"
 	"time"
+
+	"github.com/lib/pq"
 )
 
 type PostgresDataBase struct {
@@ -12,7 +18,50 @@ type PostgresDataBase struct {
 }
 
 type PostgresColumn struct {
-	BaseColumn
+	BaseWidthColumn
+	isArray bool
+}
+
+// serialTypes maps integer types to the SERIAL type generating their values.
+var serialTypes = map[string]string{"SMALLINT": "SMALLSERIAL", "INTEGER": "SERIAL", "BIGINT": "BIGSERIAL"}
+
+// isSerialColumn reports whether the auto-increment column col is tagged serial.
+func isSerialColumn(col ColumnInterface) bool {
+	_, ok := serialTypes[strings.ToUpper(col.Type())]
+	return ok && tagFlag(col.GetStructTags(), TAG_SERIAL)
+}
+
+// ScanTarget returns the destination passed to Rows.Scan to read the column into field
+func (c *PostgresColumn) ScanTarget(field reflect.Value) interface{} {
+	if c.isArray && field.Type().Elem().Kind() == reflect.Int {
+		return &intArrayScanner{dst: field}
+	}
+	if c.isArray {
+		return pq.Array(field.Addr().Interface())
+	}
+	return c.BaseColumn.ScanTarget(field)
+}
+
+// intArrayScanner reads BIGINT[] values into []int fields, pq only scans arrays of sized integers.
+type intArrayScanner struct {
+	dst reflect.Value
+}
+
+func (s *intArrayScanner) Scan(src interface{}) error {
+	var values pq.Int64Array
+	if err := values.Scan(src); err != nil {
+		return err
+	}
+	if values == nil {
+		s.dst.Set(reflect.Zero(s.dst.Type()))
+		return nil
+	}
+	ints := reflect.MakeSlice(s.dst.Type(), len(values), len(values))
+	for i, v := range values {
+		ints.Index(i).SetInt(v)
+	}
+	s.dst.Set(ints)
+	return nil
 }
 
 // GetName returns the name of the database type.
@@ -25,49 +74,29 @@ func (postgres *PostgresDataBase) GetDB() *DataBase {
 	return &postgres.DataBase
 }
 
-// GetTables returns the DDL information for all tables in the database.
-func (postgres *PostgresDataBase) GetTables() ([]Table, error) {
-	ret := make([]Table, 0)
-	tableNames, err := postgres.getTableNames()
+// GetTables returns the DDL information for all tables in the schema.
+func (postgres *PostgresDataBase) GetTables(schema string) ([]Table, error) {
+	tables, err := postgres.introspect(schema, "")
 	if err != nil {
-		return nil, fmt.Errorf("failed to get table names: %w", err)
+		return nil, fmt.Errorf("failed to get tables: %w", err)
 	}
-	for _, tableName := range tableNames {
-		columns, err := postgres.getColumnInfo(tableName)
-		if err != nil {
-			return nil, fmt.Errorf("failed to get columns for table %s: %w", tableName, err)
-		}
-		table := Table{
-			name:         tableName,
-			columns:      columns,
-			db:           postgres,
-			extraOptions: make(map[string]string),
-		}
-		ret = append(ret, table)
+	ret := make([]Table, 0, len(tables))
+	for _, table := range tables {
+		ret = append(ret, *table)
 	}
 	return ret, nil
 }
 
-func (postgres *PostgresDataBase) GetCreateTableSQL(tableName string, columns []ColumnInterface) string {
-	sql := fmt.Sprintf("CREATE TABLE %s (", tableName)
+func (postgres *PostgresDataBase) GetCreateTableSQL(schema, tableName string, columns []ColumnInterface, options CreateTableOptions) string {
+	sql := fmt.Sprintf("CREATE TABLE %s (", postgres.QuoteIdentifier(schema, tableName))
 	var primaryKeys []string
 	
 	for i, col := range columns {
-		sql += fmt.Sprintf("%s %s", col.Name(), col.Type())
-		
-		// Add NOT NULL if the column is not nullable
-		if !col.Nullable() {
-			sql += " NOT NULL"
-		}
-		
-		// Add DEFAULT if specified
-		if col.Default() != "" {
-			sql += fmt.Sprintf(" DEFAULT %s", col.Default())
-		}
+		sql += fmt.Sprintf("%s %s", postgres.QuoteIdentifier(col.Name()), postgres.ColumnDefinitionSql(schema, col))
 		
 		// Collect primary key columns
 		if col.IsPrimaryKey() {
-			primaryKeys = append(primaryKeys, col.Name())
+			primaryKeys = append(primaryKeys, postgres.QuoteIdentifier(col.Name()))
 		}
 		
 		if i < len(columns)-1 {
@@ -79,15 +108,141 @@ func (postgres *PostgresDataBase) GetCreateTableSQL(tableName string, columns []
 	if len(primaryKeys) > 0 {
 		sql += fmt.Sprintf(", PRIMARY KEY (%s)", strings.Join(primaryKeys, ", "))
 	}
+	sql += checkConstraintsSQL(postgres, options.Checks)
 	
 	sql += ");"
+
+	// SERIAL columns have no start value, their sequence is moved to the offset instead
+	for _, col := range columns {
+		if col.IsAutoIncrement() && col.AutoIncrementOffset() > 0 && isSerialColumn(col) {
+			sql += fmt.Sprintf(" SELECT setval(pg_get_serial_sequence(%s, %s), %d, false);",
+				quoteLiteral(postgres.QuoteIdentifier(schema, tableName)), quoteLiteral(col.Name()), col.AutoIncrementOffset())
+		}
+	}
+
+	// Comments are not part of the table definition on PostgreSQL
+	if options.Comment != "" {
+		sql += fmt.Sprintf(" COMMENT ON TABLE %s IS %s;", postgres.QuoteIdentifier(schema, tableName), quoteLiteral(options.Comment))
+	}
+	for _, col := range columns {
+		if col.Comment() != "" {
+			sql += fmt.Sprintf(" COMMENT ON COLUMN %s IS %s;", postgres.QuoteIdentifier(schema, tableName, col.Name()), quoteLiteral(col.Comment()))
+		}
+	}
+	return sql
+}
+
+// CollationSql returns the COLLATE clause of collation, the character set is the encoding of the
+// database on PostgreSQL.
+func (postgres *PostgresDataBase) CollationSql(charset, collation string) string {
+	if collation == "" {
+		return ""
+	}
+	return " COLLATE " + postgres.QuoteIdentifier(collation)
+}
+
+// ColumnDefinitionSql returns the type of col with its NOT NULL and DEFAULT constraints.
+func (postgres *PostgresDataBase) ColumnDefinitionSql(schema string, col ColumnInterface) string {
+	sql := columnTypeSQL(postgres, schema, col)
+	if !col.Nullable() {
+		sql += " NOT NULL"
+	}
+	if col.Default() != "" {
+		sql += fmt.Sprintf(" DEFAULT %s", col.Default())
+	}
 	return sql
 }
 
+// QuoteIdentifier quotes identifiers with double quotes, quoted identifiers keep their case
+// and may be reserved words.
+func (postgres *PostgresDataBase) QuoteIdentifier(parts ...string) string {
+	return quoteIdentifier(`"`, parts...)
+}
+
+func (postgres *PostgresDataBase) CreateSchemaSql(schema string) string {
+	return fmt.Sprintf("CREATE SCHEMA IF NOT EXISTS %s;", postgres.QuoteIdentifier(schema))
+}
+
 func (postgres *PostgresDataBase) IsSupportForeignKeys() bool {
 	return true
 }
 
+// SearchVectorSql concatenates the columns into a tsvector of the text search configuration.
+func (postgres *PostgresDataBase) SearchVectorSql(columns []string, config string) string {
+	parts := make([]string, len(columns))
+	for i, column := range columns {
+		parts[i] = fmt.Sprintf("coalesce(%s, '')", column)
+	}
+	return fmt.Sprintf("to_tsvector(%s, %s)", quoteLiteral(config), strings.Join(parts, " || ' ' || "))
+}
+
+// MaxIdentifierLength is NAMEDATALEN - 1, PostgreSQL silently truncates longer identifiers.
+func (postgres *PostgresDataBase) MaxIdentifierLength() int {
+	return 63
+}
+
+// AutoIncrementTypeSql returns an identity column starting at the offset of col, or a SERIAL
+// type when col is tagged serial.
+func (postgres *PostgresDataBase) AutoIncrementTypeSql(col ColumnInterface) string {
+	if isSerialColumn(col) {
+		return serialTypes[strings.ToUpper(col.Type())]
+	}
+	sqlType := col.Type() + " GENERATED BY DEFAULT AS IDENTITY"
+	if offset := col.AutoIncrementOffset(); offset > 0 {
+		sqlType += fmt.Sprintf(" (START WITH %d)", offset)
+	}
+	return sqlType
+}
+
+func (postgres *PostgresDataBase) IsSupportEnumTypes() bool {
+	return true
+}
+
+// GetEnumTypes returns the labels of the enum types in schema in their sort order.
+func (postgres *PostgresDataBase) GetEnumTypes(schema string) (map[string][]string, error) {
+	query := `
+		SELECT t.typname, e.enumlabel
+		FROM
+			pg_type t
+			JOIN pg_enum e ON e.enumtypid = t.oid
+			JOIN pg_namespace n ON n.oid = t.typnamespace
+		WHERE n.nspname = COALESCE(NULLIF($1, ''), current_schema())
+		ORDER BY t.typname, e.enumsortorder;
+	`
+	rows, err := postgres.db.Query(query, schema)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	enums := make(map[string][]string)
+	for rows.Next() {
+		var typeName, label string
+		if err := rows.Scan(&typeName, &label); err != nil {
+			return nil, err
+		}
+		enums[typeName] = append(enums[typeName], label)
+	}
+	return enums, rows.Err()
+}
+
+func (postgres *PostgresDataBase) IsSupportIndexOptions() bool {
+	return true
+}
+
+// IndexMethod accepts the built-in access methods of PostgreSQL, they are lower case.
+func (postgres *PostgresDataBase) IndexMethod(method string) (string, error) {
+	switch method = strings.ToLower(method); method {
+	case "", "btree":
+		return "btree", nil
+	case "hash", "gin", "gist", "spgist", "brin":
+		return method, nil
+	}
+	return "", fmt.Errorf("unsupported index type %q for database: %s", method, postgres.Name())
+}
+
 func (postgres *PostgresDataBase) GetTablesColumns(t TableInterface) ([]ColumnInterface, error) {
 	ret := make([]ColumnInterface, 0)
 	for _, col := range t.Columns() {
@@ -102,103 +257,214 @@ func (postgres *PostgresDataBase) GetTablesColumns(t TableInterface) ([]ColumnIn
 }
 
 func (postgres *PostgresDataBase) GetColumnDefinitionByType(fieldType reflect.Type, columnName string, tag map[string]string, isPointer bool) (ColumnInterface, error) {
-	retCol := PostgresColumn{}
-	retCol.name = columnName
-	if isPointer {
-		retCol.isPointer = true
-	} else {
-		retCol.isPointer = false
-	}
-
 	// Handle pointer types by getting the underlying type
 	actualType := fieldType
 	if fieldType.Kind() == reflect.Ptr {
 		actualType = fieldType.Elem()
-		retCol.isPointer = true
+		isPointer = true
+	}
+
+	isArray := false
+	var enum []string
+	sqlType, converter, ok := resolveCustomType(postgres.Name(), actualType, tag)
+	if !ok {
+		// enums are named types, created by Sync in the schema of the table
+		if enum, ok = enumValues(actualType); ok {
+			sqlType = enumTypeName(actualType)
+		}
+	}
+	if !ok {
+		sqlType, converter, ok = postgres.nativeType(actualType, tag)
+		isArray = ok && strings.HasSuffix(sqlType, "[]")
+	}
+	isJSON := !isArray && isJSONType(actualType, tag)
+	if isJSON && converter == nil {
+		converter = jsonValueConverter
+	}
+	if !ok && isJSON {
+		sqlType = "JSONB"
+	} else if !ok {
+		var err error
+		if sqlType, err = postgres.sqlTypeByKind(actualType, tag); err != nil {
+			return nil, err
+		}
+	}
+	retCol := PostgresColumn{BaseWidthColumn: NewBaseWidthColumn(columnName, sqlType, tag, isPointer)}
+	if _, ok := tag[TAG_NULLABLE]; !ok {
+		// PostgreSQL columns are NOT NULL unless tagged nullable, pointer fields included
+		retCol.isNullable = false
+	}
+	if timeTypes[strings.ToUpper(sqlType)] && retCol.precision == 6 {
+		// 6 is the default fractional seconds precision, it is introspected as undeclared
+		retCol.precision = 0
+	}
+	retCol.converter = converter
+	retCol.parser = textParserFor(actualType)
+	retCol.isJSON = isJSON
+	retCol.isArray = isArray
+	retCol.enumValues = enum
+	return &retCol, nil
+}
+
+// postgresArrayElemTypes maps the element kinds of slices stored as native arrays.
+var postgresArrayElemTypes = map[reflect.Kind]string{
+	reflect.String:  "TEXT",
+	reflect.Int64:   "BIGINT",
+	reflect.Int32:   "INTEGER",
+	reflect.Int:     "BIGINT",
+	reflect.Float64: "DOUBLE PRECISION",
+	reflect.Float32: "REAL",
+	reflect.Bool:    "BOOLEAN",
+}
+
+// nativeType maps field types to PostgreSQL specific column types: UUID, INET, NUMERIC and arrays.
+func (postgres *PostgresDataBase) nativeType(actualType reflect.Type, tag map[string]string) (string, ValueConverter, bool) {
+	if _, ok := tag[TAG_JSON]; ok {
+		return "", nil, false
+	}
+	isValuer := isCustomValueType(actualType)
+	if _, ok := tag[TAG_UUID]; ok || (actualType.ConvertibleTo(uuidArrayType) && actualType.Kind() == reflect.Array) {
+		if isValuer || actualType.Kind() != reflect.Array {
+			return "UUID", nil, true
+		}
+		return "UUID", uuidValueConverter, true
+	}
+	if actualType == ipType {
+		return "INET", ipValueConverter, true
+	}
+	switch actualType {
+	case bigFloatType, bigRatType, bigIntType:
+		return "NUMERIC", bigNumberValueConverter, true
+	}
+	if _, _, ok := parsePrecision(tag); ok {
+		if actualType.Kind() == reflect.String || (actualType.Kind() == reflect.Struct && isValuer) {
+			return "NUMERIC", nil, true
+		}
 	}
+	if actualType.Kind() == reflect.Slice && !isValuer {
+		if elemType, ok := postgresArrayElemTypes[actualType.Elem().Kind()]; ok {
+			return elemType + "[]", arrayValueConverter, true
+		}
+	}
+	return "", nil, false
+}
+
+func uuidValueConverter(value interface{}) interface{} {
+	var b [16]byte
+	reflect.ValueOf(&b).Elem().Set(reflect.ValueOf(value).Convert(uuidArrayType))
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+func ipValueConverter(value interface{}) interface{} {
+	ip := value.(net.IP)
+	if ip == nil {
+		return nil
+	}
+	return ip.String()
+}
 
+func arrayValueConverter(value interface{}) interface{} {
+	return pq.Array(value)
+}
+
+// postgresColumnType builds the column type reported by information_schema in the same
+// format GetColumnDefinitionByType generates, so Sync can compare both.
+func postgresColumnType(udtName string, charLength, numericPrecision, numericScale, datetimePrecision sql.NullInt64) string {
+	if strings.HasPrefix(udtName, "_") {
+		return postgresColumnType(strings.TrimPrefix(udtName, "_"), charLength, sql.NullInt64{}, sql.NullInt64{}, sql.NullInt64{}) + "[]"
+	}
+	// 6 is the default fractional seconds precision, it is reported when none was declared
+	hasTimePrecision := datetimePrecision.Valid && datetimePrecision.Int64 != 6
+	switch udtName {
+	case "int2":
+		return "SMALLINT"
+	case "int4":
+		return "INTEGER"
+	case "int8":
+		return "BIGINT"
+	case "float4":
+		return "REAL"
+	case "float8":
+		return "DOUBLE PRECISION"
+	case "bool":
+		return "BOOLEAN"
+	case "timestamptz":
+		if hasTimePrecision {
+			return fmt.Sprintf("TIMESTAMP(%d) WITH TIME ZONE", datetimePrecision.Int64)
+		}
+		return "TIMESTAMP WITH TIME ZONE"
+	case "timestamp":
+		if hasTimePrecision {
+			return fmt.Sprintf("TIMESTAMP(%d)", datetimePrecision.Int64)
+		}
+		return "TIMESTAMP"
+	case "varchar":
+		if charLength.Valid {
+			return fmt.Sprintf("VARCHAR(%d)", charLength.Int64)
+		}
+		return "VARCHAR"
+	case "bpchar":
+		if charLength.Valid {
+			return fmt.Sprintf("CHAR(%d)", charLength.Int64)
+		}
+		return "CHAR"
+	case "numeric":
+		if numericPrecision.Valid {
+			return fmt.Sprintf("NUMERIC(%d,%d)", numericPrecision.Int64, numericScale.Int64)
+		}
+		return "NUMERIC"
+	}
+	return strings.ToUpper(udtName)
+}
+
+// sqlTypeByKind maps the kind of a field type to the PostgreSQL column type.
+func (postgres *PostgresDataBase) sqlTypeByKind(actualType reflect.Type, tag map[string]string) (string, error) {
 	switch actualType.Kind() {
 	case reflect.String:
-		retCol.sqlType = "TEXT"
+		return stringTypeByWidth(tag), nil
 	case reflect.Int8:
-		retCol.sqlType = "SMALLINT"
+		return "SMALLINT", nil
 	case reflect.Int16:
-		retCol.sqlType = "SMALLINT"
+		return "SMALLINT", nil
 	case reflect.Int32, reflect.Int:
-		retCol.sqlType = "INTEGER"
+		return "INTEGER", nil
 	case reflect.Int64:
-		retCol.sqlType = "BIGINT"
+		return "BIGINT", nil
 	case reflect.Uint8:
-		retCol.sqlType = "SMALLINT"
+		return "SMALLINT", nil
 	case reflect.Uint16:
-		retCol.sqlType = "INTEGER"
+		return "INTEGER", nil
 	case reflect.Uint32, reflect.Uint:
-		retCol.sqlType = "BIGINT"
+		return "BIGINT", nil
 	case reflect.Uint64:
-		retCol.sqlType = "BIGINT" // Note: PostgreSQL doesn't have unsigned types
-	case reflect.Float32:
-		retCol.sqlType = "REAL"
-	case reflect.Float64:
-		retCol.sqlType = "DOUBLE PRECISION"
+		return "BIGINT", nil // Note: PostgreSQL doesn't have unsigned types
+	case reflect.Float32, reflect.Float64:
+		if _, _, ok := parsePrecision(tag); ok {
+			return "NUMERIC", nil
+		}
+		if actualType.Kind() == reflect.Float32 {
+			return "REAL", nil
+		}
+		return "DOUBLE PRECISION", nil
 	case reflect.Bool:
-		retCol.sqlType = "BOOLEAN"
+		return "BOOLEAN", nil
 	case reflect.Slice:
 		if actualType == reflect.TypeOf([]byte{}) {
-			retCol.sqlType = "BYTEA"
-		} else {
-			return nil, fmt.Errorf("unsupported slice type: %s", actualType.String())
+			return "BYTEA", nil
 		}
+		return "", fmt.Errorf("unsupported slice type: %s", actualType.String())
 	case reflect.Struct:
 		if actualType == reflect.TypeOf(time.Time{}) {
-			retCol.sqlType = "TIMESTAMP WITH TIME ZONE"
-		} else {
-			return nil, fmt.Errorf("unsupported struct type: %s", actualType.Name())
+			return "TIMESTAMP WITH TIME ZONE", nil
 		}
+		return "", unsupportedStructTypeError(actualType)
 	default:
-		return nil, fmt.Errorf("unsupported field type: %s", actualType.Kind().String())
+		return "", fmt.Errorf("unsupported field type: %s", actualType.Kind().String())
 	}
-	if defaultValue, ok := tag["default"]; ok {
-		retCol.defaultString = defaultValue
-	} else {
-		retCol.defaultString = ""
-	}
-	if nullable, ok := tag[TAG_NULLABLE]; ok && (nullable == "" || nullable == "true" || nullable == "1") {
-		retCol.isNullable = true
-	} else {
-		retCol.isNullable = false
-	}
-	if primaryKey, ok := tag[TAG_PRIMARY]; ok && (primaryKey == "" || primaryKey == "true" || primaryKey == "1") {
-		retCol.isPrimaryKey = true
-	} else {
-		retCol.isPrimaryKey = false
-	}
-	if unique, ok := tag[TAG_UNIQUE]; ok && (unique == "" || unique == "true" || unique == "1") {
-		retCol.isUnique = true
-	} else {
-		retCol.isUnique = false
-	}
-	if index, ok := tag[TAG_INDEX]; ok && (index == "" || index == "true" || index == "1") {
-		retCol.isIndex = true
-	} else {
-		retCol.isIndex = false
-	}
-	if allowZero, ok := tag[TAG_ALLOW_ZERO]; ok && (allowZero == "" || allowZero == "true" || allowZero == "1") {
-		retCol.isAllowZero = true
-	} else {
-		retCol.isAllowZero = false
-	}
-	retCol.tags = tag
-	retCol.columnIndex = -1 // Default value, can be set later if needed
-	if name, ok := tag[TAG_NAME]; ok {
-		retCol.name = name
-	} else {
-		retCol.name = columnName
-	}
-	return &retCol, nil
 }
 
-func (postgres *PostgresDataBase) DropTableSql(tableName string) string {
-	return fmt.Sprintf("DROP TABLE IF EXISTS %s;", tableName)
+func (postgres *PostgresDataBase) DropTableSql(schema, tableName string) string {
+	return fmt.Sprintf("DROP TABLE IF EXISTS %s;", postgres.QuoteIdentifier(schema, tableName))
 }
 
 func (postgres *PostgresDataBase) CanInsert() bool {
@@ -217,8 +483,12 @@ func (postgres *PostgresDataBase) CanReturnRowsAffected() bool {
 	return true
 }
 
+func (postgres *PostgresDataBase) CanInsertReturning() bool {
+	return true
+}
+
 func (postgres *PostgresDataBase) InsertSqlTemplate() string {
-	tpl := ("INSERT INTO {{.TableName}} ({{.Columns}}) VALUES ({{.Values}});")
+	tpl := ("INSERT INTO {{.TableName}} ({{.Columns}}) VALUES ({{.Values}}){{if .Returning}} RETURNING {{.Returning}}{{end}};")
 	return tpl
 }
 
@@ -227,6 +497,23 @@ func (postgres *PostgresDataBase) UpdateSqlTemplate() string {
 	return tpl
 }
 
+func (postgres *PostgresDataBase) SelectSqlTemplate() string {
+	tpl := ("SELECT {{.Columns}} FROM {{.TableName}} WHERE {{.Conditions}};")
+	return tpl
+}
+
+func (postgres *PostgresDataBase) QuerySqlTemplate() string {
+	return "SELECT {{.Columns}} FROM {{.TableName}}{{if .Conditions}} WHERE {{.Conditions}}{{end}}{{if .OrderBy}} ORDER BY {{.OrderBy}}{{end}}{{if .Limit}} LIMIT {{.Limit}}{{end}}{{if .Offset}} OFFSET {{.Offset}}{{end}};"
+}
+
+func (postgres *PostgresDataBase) SearchSqlTemplate() string {
+	return "{{.Columns}} @@ plainto_tsquery({{.SearchConfig}}, {{.Values}})"
+}
+
+func (postgres *PostgresDataBase) SearchRankSqlTemplate() string {
+	return "ts_rank({{.Columns}}, plainto_tsquery({{.SearchConfig}}, {{.Values}}))"
+}
+
 func (postgres *PostgresDataBase) InsertOrUpdateSqlTemplate() string {
 	tpl := ("INSERT INTO {{.TableName}} ({{.Columns}}) VALUES ({{.Values}}) ON CONFLICT ({{.ConflictColumns}}) DO UPDATE SET {{.Updates}};")
 	return tpl
@@ -236,79 +523,248 @@ func (postgres *PostgresDataBase) CanRenameTable() bool {
 	return true
 }
 
-func (postgres *PostgresDataBase) GetTableDDL(tableName string) (*Table, error) {
-	table := &Table{
-		name:        tableName,
-		columns:     make([]ColumnInterface, 0),
-		indexes:     make([]TableIndex, 0),
-		constraints: make([]TableForeignKey, 0),
-		db:          postgres,
+func (postgres *PostgresDataBase) GetTableDDL(schema, tableName string) (*Table, error) {
+	tables, err := postgres.introspect(schema, tableName)
+	if err != nil {
+		return nil, err
+	}
+	if len(tables) == 0 {
+		return nil, nil // Table doesn't exist
+	}
+	return tables[0], nil
+}
+
+// introspect loads the columns, indexes and foreign keys of all tables in schema with a single
+// query each, a non-empty tableName restricts it to that table.
+func (postgres *PostgresDataBase) introspect(schema, tableName string) ([]*Table, error) {
+	columns, tableNames, err := postgres.getColumns(schema, tableName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get columns: %w", err)
+	}
+	indexes, err := postgres.getIndexes(schema, tableName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get indexes: %w", err)
+	}
+	foreignKeys, err := postgres.getForeignKeys(schema, tableName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get foreign keys: %w", err)
+	}
+	checks, err := postgres.getChecks(schema, tableName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get check constraints: %w", err)
 	}
-	columnMap := make(map[string]*PostgresColumn)
-	columnQuery := `
+	comments, err := postgres.getTableComments(schema, tableName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get table comments: %w", err)
+	}
+	tables := make([]*Table, 0, len(tableNames))
+	for _, name := range tableNames {
+		tables = append(tables, &Table{
+			schema:       schema,
+			name:         name,
+			columns:      columns[name],
+			indexes:      indexes[name],
+			constraints:  foreignKeys[name],
+			checks:       checks[name],
+			comment:      comments[name],
+			db:           postgres,
+			extraOptions: make(map[string]string),
+		})
+	}
+	return tables, nil
+}
+
+// getColumns returns the columns of the base tables in schema by table name, and the table
+// names in order.
+func (postgres *PostgresDataBase) getColumns(schema, tableName string) (map[string][]ColumnInterface, []string, error) {
+	query := `
 		SELECT
-			column_name,
-			udt_name,
-			is_nullable,
-			column_default
+			c.table_name,
+			c.column_name,
+			c.udt_name,
+			c.character_maximum_length,
+			c.numeric_precision,
+			c.numeric_scale,
+			c.datetime_precision,
+			c.is_nullable,
+			c.column_default,
+			COALESCE(c.generation_expression, ''),
+			c.is_identity,
+			CASE WHEN c.is_identity = 'YES' OR c.column_default LIKE 'nextval(%' THEN
+				pg_sequence_last_value(pg_get_serial_sequence(format('%I.%I', c.table_schema, c.table_name), c.column_name)::regclass)
+			END,
+			COALESCE(col_description(a.attrelid, a.attnum), ''),
+			COALESCE(c.collation_name, '')
 		FROM
-			information_schema.columns
+			information_schema.columns c
+			JOIN information_schema.tables t ON t.table_schema = c.table_schema AND t.table_name = c.table_name
+			LEFT JOIN pg_attribute a ON a.attrelid = format('%I.%I', c.table_schema, c.table_name)::regclass AND a.attname = c.column_name
 		WHERE
-			table_schema = $1 AND table_name = $2
+			c.table_schema = COALESCE(NULLIF($1, ''), current_schema())
+			AND ($2 = '' OR c.table_name = $2)
+			AND t.table_type = 'BASE TABLE'
 		ORDER BY
-			ordinal_position;
+			c.table_name, c.ordinal_position;
 	`
-	rows, err := postgres.db.Query(columnQuery, "public", tableName)
+	enums, err := postgres.GetEnumTypes(schema)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
+	}
+	rows, err := postgres.db.Query(query, schema, tableName)
+	if err != nil {
+		return nil, nil, err
 	}
 	defer func() {
 		_ = rows.Close()
 	}()
 
+	columns := make(map[string][]ColumnInterface)
+	var tableNames []string
 	for rows.Next() {
-		var colName, dataType, isNullable string
-		var defaultValue *string
-		if err := rows.Scan(&colName, &dataType, &isNullable, &defaultValue); err != nil {
-			return nil, err
+		var table, colName, udtName, isNullable, generated, isIdentity, comment, collation string
+		var charLength, numericPrecision, numericScale, datetimePrecision, lastValue sql.NullInt64
+		var defaultValue sql.NullString
+		if err := rows.Scan(&table, &colName, &udtName, &charLength, &numericPrecision, &numericScale, &datetimePrecision, &isNullable, &defaultValue, &generated, &isIdentity, &lastValue, &comment, &collation); err != nil {
+			return nil, nil, err
 		}
-
-		defaultStr := ""
-		if defaultValue != nil {
-			defaultStr = *defaultValue
+		if _, ok := columns[table]; !ok {
+			tableNames = append(tableNames, table)
 		}
 
+		columnType := postgresColumnType(udtName, charLength, numericPrecision, numericScale, datetimePrecision)
+		if _, ok := enums[udtName]; ok {
+			columnType = udtName
+		}
 		column := &PostgresColumn{
-			BaseColumn: BaseColumn{
+			BaseWidthColumn: newWidthColumnFromType(BaseColumn{
 				name:          colName,
-				sqlType:       dataType,
 				isNullable:    isNullable == "YES",
-				defaultString: defaultStr,
-			},
+				defaultString: defaultValue.String,
+				generated:     generated,
+				enumValues:    enums[udtName],
+				comment:       comment,
+				collation:     collation,
+			}, columnType),
+			isArray: strings.HasPrefix(udtName, "_"),
+		}
+		// SERIAL columns default to the next value of their sequence, the default is part of
+		// the auto-increment and not compared by Sync
+		if isSerial := strings.HasPrefix(defaultValue.String, "nextval("); isIdentity == "YES" || isSerial {
+			column.SetAutoIncrement(true)
+			if lastValue.Valid {
+				// the sequence returns NULL until its first value is used
+				column.SetAutoIncrementOffset(lastValue.Int64 + 1)
+			}
+			if isSerial {
+				column.SetDefault("")
+			}
 		}
-		columnMap[colName] = column
+		columns[table] = append(columns[table], column)
 	}
-	if err := rows.Err(); err != nil {
+	return columns, tableNames, rows.Err()
+}
+
+// getIndexes returns the indexes of the tables in schema by table name, primary keys excluded.
+// Expressions, sort orders, methods, INCLUDE columns and predicates are loaded so Sync can compare them.
+func (postgres *PostgresDataBase) getIndexes(schema, tableName string) (map[string][]TableIndex, error) {
+	query := `
+		SELECT
+			t.relname,
+			i.relname,
+			ix.indisunique,
+			ix.indisvalid,
+			COALESCE(a.attname, ''),
+			pg_get_indexdef(ix.indexrelid, k.ord, true),
+			k.ord > ix.indnkeyatts,
+			ix.indoption[k.ord - 1],
+			COALESCE(pg_get_expr(ix.indpred, ix.indrelid, true), ''),
+			am.amname
+		FROM
+			pg_index ix
+			JOIN pg_class i ON i.oid = ix.indexrelid
+			JOIN pg_am am ON am.oid = i.relam
+			JOIN pg_class t ON t.oid = ix.indrelid
+			JOIN pg_namespace n ON n.oid = t.relnamespace
+			CROSS JOIN LATERAL generate_series(1, ix.indnatts::int) AS k(ord)
+			LEFT JOIN pg_attribute a ON a.attrelid = t.oid AND a.attnum = ix.indkey[k.ord - 1] AND a.attnum > 0
+		WHERE
+			n.nspname = COALESCE(NULLIF($1, ''), current_schema())
+			AND ($2 = '' OR t.relname = $2)
+			AND NOT ix.indisprimary
+		ORDER BY
+			t.relname, i.relname, k.ord;
+	`
+	rows, err := postgres.db.Query(query, schema, tableName)
+	if err != nil {
 		return nil, err
 	}
-	if len(columnMap) == 0 {
-		return nil, nil // Table doesn't exist
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	indexes := make(map[string][]TableIndex)
+	for rows.Next() {
+		var table, indexName, columnName, definition, predicate, method string
+		var isUnique, isValid, included bool
+		var option sql.NullInt64
+		if err := rows.Scan(&table, &indexName, &isUnique, &isValid, &columnName, &definition, &included, &option, &predicate, &method); err != nil {
+			return nil, err
+		}
+		tableIndexes := indexes[table]
+		n := len(tableIndexes)
+		if n == 0 || tableIndexes[n-1].name != indexName {
+			tableIndexes = append(tableIndexes, TableIndex{
+				name:     indexName,
+				isUnique: isUnique,
+				where:    predicate,
+				method:   method,
+				// a failed CREATE INDEX CONCURRENTLY leaves an invalid index behind
+				invalid: !isValid,
+			})
+			n++
+		}
+		if included {
+			tableIndexes[n-1].include = append(tableIndexes[n-1].include, columnName)
+		} else {
+			tableIndexes[n-1].columns = append(tableIndexes[n-1].columns, postgresIndexColumn(columnName, definition, option.Int64))
+		}
+		indexes[table] = tableIndexes
 	}
+	return indexes, rows.Err()
+}
 
-	table.columns = make([]ColumnInterface, 0, len(columnMap))
-	for _, col := range columnMap {
-		table.columns = append(table.columns, col)
+// postgresIndexColumn builds an index key part from pg_index. Expressions have no column
+// name, the bits of option are 1 for DESC and 2 for NULLS FIRST. NULLS order is only kept
+// when it is not the default: LAST for ascending and FIRST for descending columns.
+func postgresIndexColumn(columnName, definition string, option int64) IndexColumn {
+	col := IndexColumn{Column: columnName, Desc: option&1 != 0}
+	if columnName == "" {
+		col.Expression = definition
+	}
+	nullsFirst := option&2 != 0
+	if nullsFirst != col.Desc {
+		col.Nulls = "LAST"
+		if nullsFirst {
+			col.Nulls = "FIRST"
+		}
 	}
-	return table, nil
+	return col
 }
 
-func (postgres *PostgresDataBase) getTableNames() ([]string, error) {
+// getTableComments returns the comments of the tables in schema by table name.
+func (postgres *PostgresDataBase) getTableComments(schema, tableName string) (map[string]string, error) {
 	query := `
-		SELECT tablename
-		FROM pg_catalog.pg_tables
-		WHERE schemaname != 'pg_catalog' AND schemaname != 'information_schema';
+		SELECT c.relname, obj_description(c.oid, 'pg_class')
+		FROM
+			pg_class c
+			JOIN pg_namespace n ON n.oid = c.relnamespace
+		WHERE
+			n.nspname = COALESCE(NULLIF($1, ''), current_schema())
+			AND ($2 = '' OR c.relname = $2)
+			AND c.relkind IN ('r', 'p')
+			AND obj_description(c.oid, 'pg_class') IS NOT NULL;
 	`
-	rows, err := postgres.db.Query(query)
+	rows, err := postgres.db.Query(query, schema, tableName)
 	if err != nil {
 		return nil, err
 	}
@@ -316,25 +772,32 @@ func (postgres *PostgresDataBase) getTableNames() ([]string, error) {
 		_ = rows.Close()
 	}()
 
-	var tables []string
+	comments := make(map[string]string)
 	for rows.Next() {
-		var tableName string
-		if err := rows.Scan(&tableName); err != nil {
+		var table, comment string
+		if err := rows.Scan(&table, &comment); err != nil {
 			return nil, err
 		}
-		tables = append(tables, tableName)
+		comments[table] = comment
 	}
-	return tables, nil
+	return comments, rows.Err()
 }
 
-func (postgres *PostgresDataBase) getColumnInfo(tableName string) ([]ColumnInterface, error) {
+// getChecks returns the CHECK constraints of the tables in schema by table name.
+func (postgres *PostgresDataBase) getChecks(schema, tableName string) (map[string][]TableCheck, error) {
 	query := `
-		SELECT column_name, data_type, is_nullable, column_default
-		FROM information_schema.columns
-		WHERE table_name = $1
-		ORDER BY ordinal_position;
+		SELECT t.relname, c.conname, pg_get_constraintdef(c.oid, true)
+		FROM
+			pg_constraint c
+			JOIN pg_class t ON t.oid = c.conrelid
+			JOIN pg_namespace n ON n.oid = t.relnamespace
+		WHERE
+			n.nspname = COALESCE(NULLIF($1, ''), current_schema())
+			AND ($2 = '' OR t.relname = $2)
+			AND c.contype = 'c'
+		ORDER BY t.relname, c.conname;
 	`
-	rows, err := postgres.db.Query(query, tableName)
+	rows, err := postgres.db.Query(query, schema, tableName)
 	if err != nil {
 		return nil, err
 	}
@@ -342,40 +805,143 @@ func (postgres *PostgresDataBase) getColumnInfo(tableName string) ([]ColumnInter
 		_ = rows.Close()
 	}()
 
-	var columns []ColumnInterface
+	checks := make(map[string][]TableCheck)
 	for rows.Next() {
-		var colName, dataType, isNullable, defaultValue string
-		if err := rows.Scan(&colName, &dataType, &isNullable, &defaultValue); err != nil {
+		var table, name, definition string
+		if err := rows.Scan(&table, &name, &definition); err != nil {
 			return nil, err
 		}
+		checks[table] = append(checks[table], TableCheck{name: name, expression: postgresCheckExpression(definition)})
+	}
+	return checks, rows.Err()
+}
 
-		column := &PostgresColumn{
-			BaseColumn: BaseColumn{
-				name:          colName,
-				sqlType:       dataType,
-				isNullable:    isNullable == "YES",
-				defaultString: defaultValue,
-			},
-		}
-		columns = append(columns, column)
+// postgresCheckExpression extracts the condition of a constraint definition printed by
+// pg_get_constraintdef, eg: CHECK (price >= 0::numeric) NOT VALID.
+func postgresCheckExpression(definition string) string {
+	expression := strings.TrimPrefix(definition, "CHECK ")
+	expression = strings.TrimSuffix(expression, " NOT VALID")
+	return strings.TrimSuffix(expression, " NO INHERIT")
+}
 
+// getForeignKeys returns the foreign key constraints of the tables in schema by table name,
+// only their names are loaded.
+func (postgres *PostgresDataBase) getForeignKeys(schema, tableName string) (map[string][]TableForeignKey, error) {
+	query := `
+		SELECT table_name, constraint_name
+		FROM information_schema.table_constraints
+		WHERE
+			table_schema = COALESCE(NULLIF($1, ''), current_schema())
+			AND ($2 = '' OR table_name = $2)
+			AND constraint_type = 'FOREIGN KEY'
+		ORDER BY table_name, constraint_name;
+	`
+	rows, err := postgres.db.Query(query, schema, tableName)
+	if err != nil {
+		return nil, err
 	}
-	return columns, nil
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	foreignKeys := make(map[string][]TableForeignKey)
+	for rows.Next() {
+		var table, name string
+		if err := rows.Scan(&table, &name); err != nil {
+			return nil, err
+		}
+		foreignKeys[table] = append(foreignKeys[table], TableForeignKey{name: name})
+	}
+	return foreignKeys, rows.Err()
 }
 
 func (postgres *PostgresDataBase) CreateIndexSqlTemplate() string {
-	return "CREATE INDEX IF NOT EXISTS {{.IndexName}} ON {{.TableName}} ({{.Columns}});"
+	return "CREATE {{if .Unique}}UNIQUE {{end}}INDEX {{if .Concurrently}}CONCURRENTLY {{end}}{{if .IfNotExists}}IF NOT EXISTS {{end}}{{.IndexName}} ON {{.TableName}}{{if .Method}} USING {{.Method}}{{end}} ({{.Columns}}){{if .Include}} INCLUDE ({{.Include}}){{end}}{{if .Where}} WHERE {{.Where}}{{end}};"
 }
 
 func (postgres *PostgresDataBase) DropIndexSqlTemplate() string {
-	return "DROP INDEX IF EXISTS {{.IndexName}};"
+	return "DROP INDEX {{if .Concurrently}}CONCURRENTLY {{end}}IF EXISTS {{if .Schema}}{{.Schema}}.{{end}}{{.IndexName}};"
 }
 
 func (postgres *PostgresDataBase) CreateColumnSqlTemplate() string {
-	return "ALTER TABLE {{.TableName}} ADD COLUMN {{.ColumnName}} {{.ColumnType}};"
+	return "ALTER TABLE {{.TableName}} ADD COLUMN {{if .IfNotExists}}IF NOT EXISTS {{end}}{{.ColumnName}} {{.ColumnType}};{{if .Comment}} COMMENT ON COLUMN {{.TableName}}.{{.ColumnName}} IS {{.Comment}};{{end}}"
 }
 
+// UpdateColumnSqlTemplate alters the parts of the column which changed in a single statement.
 func (postgres *PostgresDataBase) UpdateColumnSqlTemplate() string {
-	return "ALTER TABLE {{.TableName}} ALTER COLUMN {{.ColumnName}} SET DATA TYPE {{.ColumnType}};"
+	return "ALTER TABLE {{.TableName}} " +
+		"{{if .TypeChanged}}ALTER COLUMN {{.ColumnName}} SET DATA TYPE {{.ColumnType}}{{if or .NullableChanged .DefaultChanged}}, {{end}}{{end}}" +
+		"{{if .NullableChanged}}ALTER COLUMN {{.ColumnName}} {{if .Nullable}}DROP{{else}}SET{{end}} NOT NULL{{if .DefaultChanged}}, {{end}}{{end}}" +
+		"{{if .DefaultChanged}}ALTER COLUMN {{.ColumnName}} {{if .Default}}SET DEFAULT {{.Default}}{{else}}DROP DEFAULT{{end}}{{end}};"
+}
+
+func (postgres *PostgresDataBase) DropColumnSqlTemplate() string {
+	return "ALTER TABLE {{.TableName}} DROP COLUMN IF EXISTS {{.ColumnName}};"
+}
+
+func (postgres *PostgresDataBase) RenameColumnSqlTemplate() string {
+	return "ALTER TABLE {{.TableName}} RENAME COLUMN {{.ColumnName}} TO {{.NewColumnName}};"
+}
+
+func (postgres *PostgresDataBase) AddForeignKeySqlTemplate() string {
+	return "ALTER TABLE {{.TableName}} ADD CONSTRAINT {{.ConstraintName}} FOREIGN KEY ({{.Columns}}) REFERENCES {{.ReferencedTable}} ({{.ReferencedColumns}}){{if .OnDelete}} ON DELETE {{.OnDelete}}{{end}};"
+}
+
+func (postgres *PostgresDataBase) AddCheckSqlTemplate() string {
+	return "ALTER TABLE {{.TableName}} ADD CONSTRAINT {{.ConstraintName}} CHECK ({{.Expression}});"
+}
+
+func (postgres *PostgresDataBase) DropCheckSqlTemplate() string {
+	return "ALTER TABLE {{.TableName}} DROP CONSTRAINT IF EXISTS {{.ConstraintName}};"
+}
+
+func (postgres *PostgresDataBase) CreateEnumSqlTemplate() string {
+	// CREATE TYPE has no IF NOT EXISTS, tables sharing the type may be synced concurrently
+	return "DO $$ BEGIN CREATE TYPE {{.TypeName}} AS ENUM ({{.Values}}); EXCEPTION WHEN duplicate_object THEN NULL; END $$;"
+}
+
+func (postgres *PostgresDataBase) AddEnumValueSqlTemplate() string {
+	return "ALTER TYPE {{.TypeName}} ADD VALUE IF NOT EXISTS {{.Values}};"
+}
+
+func (postgres *PostgresDataBase) AddAutoIncrementSqlTemplate() string {
+	return "ALTER TABLE {{.TableName}} ALTER COLUMN {{.ColumnName}} ADD GENERATED BY DEFAULT AS IDENTITY{{if .Start}} (START WITH {{.Start}}){{end}};"
+}
+
+func (postgres *PostgresDataBase) TableCommentSqlTemplate() string {
+	return "COMMENT ON TABLE {{.TableName}} IS {{if .Comment}}{{.Comment}}{{else}}NULL{{end}};"
+}
+
+func (postgres *PostgresDataBase) ColumnCommentSqlTemplate() string {
+	return "COMMENT ON COLUMN {{.TableName}}.{{.ColumnName}} IS {{if .Comment}}{{.Comment}}{{else}}NULL{{end}};"
 }
 
+// TableCharsetSqlTemplate is empty, PostgreSQL tables have no default collation.
+func (postgres *PostgresDataBase) TableCharsetSqlTemplate() string {
+	return ""
+}
+
+// AcquireLock takes a session level advisory lock on a dedicated connection.
+func (postgres *PostgresDataBase) AcquireLock(ctx context.Context, key string, timeout time.Duration) (func() error, error) {
+	conn, err := postgres.db.Conn(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get connection for lock %s: %w", key, err)
+	}
+	lockCtx, cancel := lockContext(ctx, timeout)
+	defer cancel()
+	id := advisoryLockID(key)
+	if _, err := conn.ExecContext(lockCtx, "SELECT pg_advisory_lock($1)", id); err != nil {
+		discardConn(conn)
+		if errors.Is(lockCtx.Err(), context.DeadlineExceeded) {
+			return nil, fmt.Errorf("%w: %s", ErrLockTimeout, key)
+		}
+		return nil, fmt.Errorf("failed to acquire lock %s: %w", key, err)
+	}
+	return func() error {
+		if _, err := conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock($1)", id); err != nil {
+			discardConn(conn)
+			return fmt.Errorf("failed to release lock %s: %w", key, err)
+		}
+		return conn.Close()
+	}, nil
+}
@@ -0,0 +1,188 @@
+package aaronsql
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// Enumer is implemented by string types whose values are restricted to a fixed list, eg:
+//
+//	type OrderStatus string
+//
+//	func (OrderStatus) Enum() []string { return []string{"new", "paid", "shipped"} }
+type Enumer interface {
+	Enum() []string
+}
+
+var (
+	enumRegistryLock sync.RWMutex
+	enumRegistry     = make(map[reflect.Type][]string)
+
+	enumerType = reflect.TypeOf((*Enumer)(nil)).Elem()
+)
+
+// RegisterEnum declares the named string type t as an enum of values, for types which cannot
+// implement Enumer. Registered values take precedence over the Enum method.
+func RegisterEnum(t reflect.Type, values ...string) {
+	if t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.String || t.PkgPath() == "" || len(values) == 0 {
+		panic("register enum requires a named string type and values")
+	}
+	enumRegistryLock.Lock()
+	defer enumRegistryLock.Unlock()
+	enumRegistry[t] = append([]string(nil), values...)
+}
+
+// enumValues returns the values of the enum type t, false when t is not an enum.
+func enumValues(t reflect.Type) ([]string, bool) {
+	if t.Kind() != reflect.String {
+		return nil, false
+	}
+	enumRegistryLock.RLock()
+	values, ok := enumRegistry[t]
+	enumRegistryLock.RUnlock()
+	if ok {
+		return values, true
+	}
+	if implementsEither(t, enumerType) {
+		values = reflect.New(t).Interface().(Enumer).Enum()
+		return values, len(values) > 0
+	}
+	return nil, false
+}
+
+// enumTypeName returns the name of the PostgreSQL type of the enum type t, its Go name in
+// snake case, eg: order_status for OrderStatus.
+func enumTypeName(t reflect.Type) string {
+	runes := []rune(t.Name())
+	var sb strings.Builder
+	for i, r := range runes {
+		if i > 0 && unicode.IsUpper(r) &&
+			(!unicode.IsUpper(runes[i-1]) || i+1 < len(runes) && unicode.IsLower(runes[i+1])) {
+			sb.WriteByte('_')
+		}
+		sb.WriteRune(unicode.ToLower(r))
+	}
+	return sb.String()
+}
+
+// enumValuesSQL quotes values as a list of string literals, eg: 'new', 'paid'.
+func enumValuesSQL(values []string) string {
+	quoted := make([]string, len(values))
+	for i, value := range values {
+		quoted[i] = quoteLiteral(value)
+	}
+	return strings.Join(quoted, ", ")
+}
+
+// parseEnumValues returns the values of an introspected enum column type, eg: enum('a','b').
+func parseEnumValues(columnType string) []string {
+	open, end := strings.Index(columnType, "("), strings.LastIndex(columnType, ")")
+	if open < 0 || end < open || !strings.EqualFold(strings.TrimSpace(columnType[:open]), "enum") {
+		return nil
+	}
+	var values []string
+	var value strings.Builder
+	inQuote := false
+	args := columnType[open+1 : end]
+	for i := 0; i < len(args); i++ {
+		switch c := args[i]; {
+		case c == '\'' && inQuote && i+1 < len(args) && args[i+1] == '\'':
+			value.WriteByte(c)
+			i++
+		case c == '\'':
+			if inQuote {
+				values = append(values, value.String())
+				value.Reset()
+			}
+			inQuote = !inQuote
+		case inQuote:
+			value.WriteByte(c)
+		}
+	}
+	return values
+}
+
+// validateEnumValue reports an error when the field value of the enum column col is not one of
+// its values. Nil pointers are left to the NOT NULL constraint.
+func validateEnumValue(col ColumnInterface, fieldValue reflect.Value) error {
+	values := col.EnumValues()
+	if len(values) == 0 {
+		return nil
+	}
+	if fieldValue.Kind() == reflect.Ptr {
+		if fieldValue.IsNil() {
+			return nil
+		}
+		fieldValue = fieldValue.Elem()
+	}
+	value := fieldValue.String()
+	for _, v := range values {
+		if v == value {
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid value %q for enum column %s, expected one of: %s", value, col.Name(), strings.Join(values, ", "))
+}
+
+// syncEnums creates the enum types of the columns in schema and adds the values they are
+// missing, on databases where enums are named types. Values are never removed.
+func (t *Table) syncEnums(ctx context.Context, schema string) error {
+	if !t.db.IsSupportEnumTypes() {
+		return nil
+	}
+	var existing map[string][]string
+	synced := make(map[string]bool)
+	for _, col := range t.columns {
+		values := col.EnumValues()
+		if len(values) == 0 || synced[col.Type()] {
+			continue
+		}
+		synced[col.Type()] = true
+		if existing == nil {
+			var err error
+			if existing, err = t.db.GetEnumTypes(schema); err != nil {
+				return fmt.Errorf("failed to get enum types: %w", err)
+			}
+		}
+
+		existingValues, exists := existing[col.Type()]
+		if !exists {
+			if err := t.execEnumTemplate(ctx, schema, CreateEnumTemplate, col.Type(), values); err != nil {
+				return fmt.Errorf("failed to create enum type %s: %w", col.Type(), err)
+			}
+			continue
+		}
+		known := make(map[string]bool, len(existingValues))
+		for _, v := range existingValues {
+			known[v] = true
+		}
+		for _, v := range values {
+			if known[v] {
+				continue
+			}
+			if err := t.execEnumTemplate(ctx, schema, AddEnumValueTemplate, col.Type(), []string{v}); err != nil {
+				return fmt.Errorf("failed to add value %s to enum type %s: %w", v, col.Type(), err)
+			}
+		}
+	}
+	return nil
+}
+
+func (t *Table) execEnumTemplate(ctx context.Context, schema string, name SQLTemplateName, typeName string, values []string) error {
+	enumSQL, err := renderSQL(t.db, name, SQLTemplateData{
+		TypeName: t.db.QuoteIdentifier(schema, typeName),
+		Values:   enumValuesSQL(values),
+	})
+	if err != nil {
+		return err
+	}
+	_, err = t.db.GetDB().db.ExecContext(ctx, enumSQL)
+	return err
+}
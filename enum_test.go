@@ -0,0 +1,127 @@
+package aaronsql
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type orderStatus string
+
+func (orderStatus) Enum() []string {
+	return []string{"new", "paid", "shipped"}
+}
+
+type paymentMethod string
+
+type enumOrder struct {
+	ID      int64          `db:"name:id;primary"`
+	Status  orderStatus    `db:"name:status"`
+	Payment *paymentMethod `db:"name:payment;nullable"`
+}
+
+func TestEnumColumns(t *testing.T) {
+	RegisterEnum(reflect.TypeOf(paymentMethod("")), "card", "it's cash")
+	registerTestDB(t, "enum_postgres_test", &PostgresDataBase{DataBase: DataBase{name: PostgresDB}})
+	registerTestDB(t, "enum_mariadb_test", &MariaDBDataBase{DataBase: DataBase{name: MariaDB}})
+
+	cases := []struct {
+		dbName   string
+		expected []string
+	}{
+		{"enum_postgres_test", []string{`"status" "shop"."order_status" NOT NULL`, `"payment" "shop"."payment_method",`}},
+		{"enum_mariadb_test", []string{"`status` ENUM('new', 'paid', 'shipped') NOT NULL", "`payment` ENUM('card', 'it''s cash'),"}},
+	}
+	for _, c := range cases {
+		table, err := NewTableFromStructWithDB(enumOrder{}, "orders", c.dbName)
+		if err != nil {
+			t.Fatalf("Failed to create table from struct: %v", err)
+		}
+		if values := table.Column("payment").EnumValues(); len(values) != 2 || values[1] != "it's cash" {
+			t.Errorf("Expected the registered values, got %v", values)
+		}
+		createSQL := table.db.GetCreateTableSQL("shop", table.name, table.columns, table.createTableOptions())
+		for _, expected := range c.expected {
+			if !strings.Contains(createSQL, expected) {
+				t.Errorf("Expected %s in %s", expected, createSQL)
+			}
+		}
+
+		status := table.Column("status")
+		if err := validateEnumValue(status, reflect.ValueOf(orderStatus("paid"))); err != nil {
+			t.Errorf("Expected paid to be valid, got %v", err)
+		}
+		if err := validateEnumValue(status, reflect.ValueOf(orderStatus("lost"))); err == nil {
+			t.Errorf("Expected an error for a value outside of the enum")
+		}
+		if err := validateEnumValue(table.Column("payment"), reflect.ValueOf((*paymentMethod)(nil))); err != nil {
+			t.Errorf("Expected nil pointers to be left to the NOT NULL constraint, got %v", err)
+		}
+		if err := table.Insert(&enumOrder{ID: 1, Status: "lost"}); err == nil || !strings.Contains(err.Error(), "invalid value") {
+			t.Errorf("Expected Insert to reject the value before the database, got %v", err)
+		}
+	}
+}
+
+func TestEnumHelpers(t *testing.T) {
+	type HTTPMethod string
+	names := map[reflect.Type]string{
+		reflect.TypeOf(orderStatus("")): "order_status",
+		reflect.TypeOf(HTTPMethod("")):  "http_method",
+	}
+	for typ, expected := range names {
+		if got := enumTypeName(typ); got != expected {
+			t.Errorf("Expected %s, got %s", expected, got)
+		}
+	}
+
+	values := parseEnumValues("enum('new','it''s paid','a,b')")
+	if strings.Join(values, "|") != "new|it's paid|a,b" {
+		t.Errorf("Expected the values of the column type, got %q", values)
+	}
+	if values := parseEnumValues("varchar(20)"); values != nil {
+		t.Errorf("Expected no values for other types, got %q", values)
+	}
+}
+
+func TestRenderSQLEnum(t *testing.T) {
+	postgres := &PostgresDataBase{DataBase: DataBase{name: PostgresDB}}
+	data := SQLTemplateData{TypeName: `"shop"."order_status"`, Values: enumValuesSQL([]string{"new", "paid"})}
+	got, err := renderSQL(postgres, CreateEnumTemplate, data)
+	if err != nil {
+		t.Fatalf("Failed to render create enum template: %v", err)
+	}
+	if !strings.Contains(got, `CREATE TYPE "shop"."order_status" AS ENUM ('new', 'paid');`) {
+		t.Errorf("Expected the CREATE TYPE statement, got %s", got)
+	}
+	data.Values = enumValuesSQL([]string{"shipped"})
+	if got, _ := renderSQL(postgres, AddEnumValueTemplate, data); got != `ALTER TYPE "shop"."order_status" ADD VALUE IF NOT EXISTS 'shipped';` {
+		t.Errorf("Expected the ADD VALUE statement, got %s", got)
+	}
+
+	mariadb := &MariaDBDataBase{DataBase: DataBase{name: MariaDB}}
+	if _, err := renderSQL(mariadb, CreateEnumTemplate, data); err == nil {
+		t.Errorf("Expected MariaDB to have no enum types")
+	}
+}
+
+func TestSyncMariaDBEnumValues(t *testing.T) {
+	type order struct {
+		ID     int64       `db:"name:id;primary"`
+		Status orderStatus `db:"name:status;default:'new'"`
+	}
+	runSyncCases(t, []syncCase{
+		{
+			name:  "adds values keeping the column definition",
+			db:    &MariaDBDataBase{DataBase: DataBase{name: MariaDB}},
+			model: order{},
+			existing: []ColumnInterface{
+				introspectedMariaDBColumn(BaseColumn{name: "id", isPrimaryKey: true}, "BIGINT"),
+				introspectedMariaDBColumn(BaseColumn{name: "status", defaultString: "'new'"}, "ENUM('new','paid')"),
+			},
+			expected: []string{
+				"ALTER TABLE `accounts` MODIFY COLUMN `status` ENUM('new', 'paid', 'shipped') NOT NULL DEFAULT 'new';",
+			},
+		},
+	})
+}
@@ -0,0 +1,400 @@
+package aaronsql
+
+import (
+	"strings"
+	"unicode"
+)
+
+// normalizeSQLExpression normalizes an index expression, predicate, CHECK condition or generation
+// expression for comparison. The database prints them back with casts, parentheses, quoting and
+// case of its own, eg: PostgreSQL returns ((status)::text = 'active'::text) for status = 'active',
+// so casts and identifier quotes are stripped, keywords and identifiers are lower cased and the
+// parentheses are reduced to the ones which change the grouping. String literals are kept as
+// written. Expressions the parser does not understand are compared as their token stream.
+func normalizeSQLExpression(s string) string {
+	tokens := tokenizeSQLExpression(s)
+	p := &expressionParser{tokens: tokens}
+	if node, ok := p.parseExpression(0); ok && p.pos == len(tokens) {
+		return node.String()
+	}
+	texts := make([]string, len(tokens))
+	for i, token := range tokens {
+		texts[i] = token.text
+	}
+	return strings.Join(texts, " ")
+}
+
+type sqlTokenKind int
+
+const (
+	sqlWord sqlTokenKind = iota
+	sqlLiteral
+	sqlNumber
+	sqlOperator
+	sqlPunct
+)
+
+type sqlToken struct {
+	kind sqlTokenKind
+	text string
+}
+
+// castTypeWords are the words following the first one of multi-word type names in casts.
+var castTypeWords = map[string]bool{"varying": true, "precision": true, "with": true, "without": true, "time": true, "zone": true}
+
+// tokenizeSQLExpression splits s into words, literals, numbers, operators and punctuation. Words
+// are unquoted and lower cased, casts are dropped.
+func tokenizeSQLExpression(s string) []sqlToken {
+	var tokens []sqlToken
+	runes := []rune(s)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '\'':
+			j := i + 1
+			for j < len(runes) {
+				if runes[j] == '\'' {
+					if j+1 < len(runes) && runes[j+1] == '\'' {
+						j += 2
+						continue
+					}
+					break
+				}
+				j++
+			}
+			end := j + 1
+			if end > len(runes) {
+				end = len(runes)
+			}
+			tokens = append(tokens, sqlToken{sqlLiteral, string(runes[i:end])})
+			i = end
+		case r == '"' || r == '`':
+			j := i + 1
+			for j < len(runes) && runes[j] != r {
+				j++
+			}
+			tokens = append(tokens, sqlToken{sqlWord, strings.ToLower(string(runes[i+1 : j]))})
+			i = j + 1
+		case unicode.IsDigit(r) || (r == '.' && i+1 < len(runes) && unicode.IsDigit(runes[i+1])):
+			j := i
+			for j < len(runes) && (unicode.IsDigit(runes[j]) || runes[j] == '.') {
+				j++
+			}
+			tokens = append(tokens, sqlToken{sqlNumber, string(runes[i:j])})
+			i = j
+		case unicode.IsLetter(r) || r == '_':
+			j := i
+			for j < len(runes) && (unicode.IsLetter(runes[j]) || unicode.IsDigit(runes[j]) || runes[j] == '_' || runes[j] == '$') {
+				j++
+			}
+			tokens = append(tokens, sqlToken{sqlWord, strings.ToLower(string(runes[i:j]))})
+			i = j
+		case strings.ContainsRune("(),[]", r):
+			tokens = append(tokens, sqlToken{sqlPunct, string(r)})
+			i++
+		default:
+			j := i
+			for j < len(runes) && strings.ContainsRune("+-*/<>=~!@#%^&|?:", runes[j]) {
+				j++
+			}
+			if j == i {
+				j++
+			}
+			tokens = append(tokens, sqlToken{sqlOperator, string(runes[i:j])})
+			i = j
+		}
+	}
+	return dropSQLCasts(tokens)
+}
+
+// dropSQLCasts removes ::type casts, including multi-word types, type arguments and array brackets.
+func dropSQLCasts(tokens []sqlToken) []sqlToken {
+	var kept []sqlToken
+	for i := 0; i < len(tokens); i++ {
+		if tokens[i].kind != sqlOperator || tokens[i].text != "::" || i+1 >= len(tokens) || tokens[i+1].kind != sqlWord {
+			kept = append(kept, tokens[i])
+			continue
+		}
+		i++
+		for i+1 < len(tokens) && tokens[i+1].kind == sqlWord && castTypeWords[tokens[i+1].text] {
+			i++
+		}
+		if i+1 < len(tokens) && tokens[i+1].text == "(" {
+			j := i + 2
+			for j < len(tokens) && (tokens[j].kind == sqlNumber || tokens[j].text == ",") {
+				j++
+			}
+			if j < len(tokens) && tokens[j].text == ")" {
+				i = j
+			}
+		}
+		if i+2 < len(tokens) && tokens[i+1].text == "[" && tokens[i+2].text == "]" {
+			i += 2
+		}
+	}
+	return kept
+}
+
+// sqlBinaryPrecedence is the precedence of the binary operators, higher binds tighter.
+var sqlBinaryPrecedence = map[string]int{
+	"or": 1, "and": 2,
+	"=": 4, "<>": 4, "!=": 4, "<": 4, ">": 4, "<=": 4, ">=": 4,
+	"like": 4, "ilike": 4, "in": 4, "not like": 4, "not ilike": 4, "not in": 4, "is": 4, "between": 4,
+	"+": 6, "-": 6, "*": 7, "/": 7, "%": 7,
+}
+
+// sqlOtherOperatorPrecedence is the precedence of operators not listed above, eg: || and @@.
+const sqlOtherOperatorPrecedence = 5
+
+// sqlAssociative lists the operators whose right operand needs no parentheses at the same precedence.
+var sqlAssociative = map[string]bool{"and": true, "or": true}
+
+type sqlNode interface {
+	String() string
+	precedence() int
+}
+
+type sqlAtom string
+
+func (a sqlAtom) String() string  { return string(a) }
+func (a sqlAtom) precedence() int { return 10 }
+
+type sqlUnary struct {
+	op      string
+	operand sqlNode
+}
+
+func (u sqlUnary) String() string {
+	if u.op == "not" {
+		return "not " + wrapSQLNode(u.operand, u.precedence(), false)
+	}
+	return u.op + wrapSQLNode(u.operand, u.precedence(), false)
+}
+
+func (u sqlUnary) precedence() int {
+	if u.op == "not" {
+		return 3
+	}
+	return 8
+}
+
+type sqlBinary struct {
+	op          string
+	left, right sqlNode
+}
+
+func (b sqlBinary) String() string {
+	return wrapSQLNode(b.left, b.precedence(), false) + " " + b.op + " " + wrapSQLNode(b.right, b.precedence(), !sqlAssociative[b.op])
+}
+
+func (b sqlBinary) precedence() int {
+	if p, ok := sqlBinaryPrecedence[b.op]; ok {
+		return p
+	}
+	return sqlOtherOperatorPrecedence
+}
+
+// sqlList is a function call, an array constructor or a parenthesized list, name is empty for
+// the latter two.
+type sqlList struct {
+	name        string
+	open, close string
+	items       []sqlNode
+}
+
+func (l sqlList) String() string {
+	items := make([]string, len(l.items))
+	for i, item := range l.items {
+		items[i] = item.String()
+	}
+	return l.name + l.open + strings.Join(items, ", ") + l.close
+}
+
+func (l sqlList) precedence() int { return 10 }
+
+// wrapSQLNode parenthesizes node when it binds weaker than its parent operator.
+func wrapSQLNode(node sqlNode, parent int, strict bool) string {
+	if p := node.precedence(); p < parent || (strict && p == parent) {
+		return "(" + node.String() + ")"
+	}
+	return node.String()
+}
+
+type expressionParser struct {
+	tokens []sqlToken
+	pos    int
+}
+
+func (p *expressionParser) peek(offset int) (sqlToken, bool) {
+	if p.pos+offset >= len(p.tokens) {
+		return sqlToken{}, false
+	}
+	return p.tokens[p.pos+offset], true
+}
+
+// binaryOperator returns the binary operator at the current position and its length in tokens.
+func (p *expressionParser) binaryOperator() (string, int, bool) {
+	token, ok := p.peek(0)
+	if !ok {
+		return "", 0, false
+	}
+	switch {
+	case token.kind == sqlOperator && token.text != "::":
+		return token.text, 1, true
+	case token.kind == sqlWord && token.text == "not":
+		if next, ok := p.peek(1); ok && next.kind == sqlWord && (next.text == "like" || next.text == "ilike" || next.text == "in") {
+			return "not " + next.text, 2, true
+		}
+	case token.kind == sqlWord:
+		if _, ok := sqlBinaryPrecedence[token.text]; ok {
+			return token.text, 1, true
+		}
+	}
+	return "", 0, false
+}
+
+// parseExpression parses operators binding tighter than minPrecedence with precedence climbing.
+func (p *expressionParser) parseExpression(minPrecedence int) (sqlNode, bool) {
+	left, ok := p.parseOperand()
+	if !ok {
+		return nil, false
+	}
+	for {
+		op, length, ok := p.binaryOperator()
+		if !ok {
+			return left, true
+		}
+		node := sqlBinary{op: op}
+		precedence := node.precedence()
+		if precedence <= minPrecedence {
+			return left, true
+		}
+		p.pos += length
+		switch op {
+		case "is":
+			// IS [NOT] NULL, TRUE, FALSE or DISTINCT FROM expression
+			prefix := ""
+			if token, ok := p.peek(0); ok && token.text == "not" {
+				prefix = "not "
+				p.pos++
+			}
+			token, ok := p.peek(0)
+			if !ok || token.kind != sqlWord {
+				return nil, false
+			}
+			p.pos++
+			node.left, node.right = left, sqlAtom(prefix+token.text)
+			if token.text == "distinct" {
+				if token, ok := p.peek(0); !ok || token.text != "from" {
+					return nil, false
+				}
+				p.pos++
+				right, ok := p.parseExpression(precedence)
+				if !ok {
+					return nil, false
+				}
+				node.right = sqlAtom(prefix + "distinct from " + wrapSQLNode(right, precedence, true))
+			}
+			left = node
+			continue
+		case "between":
+			low, ok := p.parseExpression(precedence)
+			if token, more := p.peek(0); !ok || !more || token.text != "and" {
+				return nil, false
+			}
+			p.pos++
+			high, ok := p.parseExpression(precedence)
+			if !ok {
+				return nil, false
+			}
+			node.right = sqlAtom(wrapSQLNode(low, precedence, true) + " and " + wrapSQLNode(high, precedence, true))
+			node.left = left
+			left = node
+			continue
+		}
+		right, ok := p.parseExpression(precedence)
+		if !ok {
+			return nil, false
+		}
+		node.left, node.right = left, right
+		left = node
+	}
+}
+
+func (p *expressionParser) parseOperand() (sqlNode, bool) {
+	token, ok := p.peek(0)
+	if !ok {
+		return nil, false
+	}
+	p.pos++
+	switch {
+	case token.kind == sqlWord && token.text == "not":
+		operand, ok := p.parseExpression(3)
+		return sqlUnary{op: "not", operand: operand}, ok
+	case token.kind == sqlOperator && (token.text == "-" || token.text == "+"):
+		operand, ok := p.parseExpression(8)
+		return sqlUnary{op: token.text, operand: operand}, ok
+	case token.text == "(":
+		items, ok := p.parseList(")")
+		if !ok || len(items) == 0 {
+			return nil, false
+		}
+		if len(items) == 1 {
+			// grouping parentheses are kept in the tree, redundant ones are not printed
+			return items[0], true
+		}
+		return sqlList{open: "(", close: ")", items: items}, true
+	case token.kind == sqlWord:
+		next, ok := p.peek(0)
+		if ok && (next.text == "(" || (next.text == "[" && token.text == "array")) {
+			p.pos++
+			closing := ")"
+			if next.text == "[" {
+				closing = "]"
+			}
+			items, ok := p.parseList(closing)
+			return sqlList{name: token.text, open: next.text, close: closing, items: items}, ok
+		}
+		if ok && next.kind == sqlLiteral {
+			// typed literals such as interval '1 day' are printed back as casts
+			p.pos++
+			return sqlAtom(next.text), true
+		}
+		return sqlAtom(token.text), true
+	case token.kind == sqlLiteral || token.kind == sqlNumber:
+		return sqlAtom(token.text), true
+	case token.kind == sqlOperator && token.text == "*":
+		return sqlAtom(token.text), true
+	}
+	return nil, false
+}
+
+// parseList parses comma separated expressions up to closing, which is consumed.
+func (p *expressionParser) parseList(closing string) ([]sqlNode, bool) {
+	var items []sqlNode
+	if token, ok := p.peek(0); ok && token.text == closing {
+		p.pos++
+		return items, true
+	}
+	for {
+		item, ok := p.parseExpression(0)
+		if !ok {
+			return nil, false
+		}
+		items = append(items, item)
+		token, ok := p.peek(0)
+		if !ok {
+			return nil, false
+		}
+		p.pos++
+		switch token.text {
+		case ",":
+		case closing:
+			return items, true
+		default:
+			return nil, false
+		}
+	}
+}
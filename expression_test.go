@@ -0,0 +1,34 @@
+package aaronsql
+
+import "testing"
+
+func TestNormalizeSQLExpression(t *testing.T) {
+	same := [][2]string{
+		{"lower(email)", "lower((email)::text)"},
+		{"deleted_at IS NULL AND status = 'active'", "((deleted_at IS NULL) AND ((status)::text = 'active'::text))"},
+		{"`status` in ('new','paid') and `price` >= 0", `"status" IN ('new', 'paid') AND price >= 0`},
+		{"a OR b AND c", "(a OR (b AND c))"},
+		{"(a + b) * c", "((a + b) * c)"},
+		{"a - (b - c)", "(a - (b - c))"},
+		{"created_at > now() - interval '1 day'", "(created_at > (now() - '1 day'::interval))"},
+		{"price::numeric(10,2) > 0", "price > 0"},
+	}
+	for _, c := range same {
+		if normalizeSQLExpression(c[0]) != normalizeSQLExpression(c[1]) {
+			t.Errorf("Expected %s to equal %s, got %s and %s", c[0], c[1], normalizeSQLExpression(c[0]), normalizeSQLExpression(c[1]))
+		}
+	}
+
+	different := [][2]string{
+		{"status = 'active'", "status = 'Active'"},
+		{"a OR b AND c", "(a OR b) AND c"},
+		{"a - b - c", "a - (b - c)"},
+		{"NOT a AND b", "NOT (a AND b)"},
+		{"code <> 'it''s'", "code <> 'It''s'"},
+	}
+	for _, c := range different {
+		if normalizeSQLExpression(c[0]) == normalizeSQLExpression(c[1]) {
+			t.Errorf("Expected %s to differ from %s, both normalized to %s", c[0], c[1], normalizeSQLExpression(c[0]))
+		}
+	}
+}
@@ -1,17 +1,62 @@
 package aaronsql
 
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
 type TableForeignKey struct {
-	name string
-	columns []string
-	referencedTable string
+	name              string
+	columns           []string
+	referencedTable   string
 	referencedColumns []string
+	// onDelete is the referential action of the constraint, eg: CASCADE, empty for the default
+	onDelete string
 }
 
 func NewTableForeignKey(name string, columns []string, referencedTable string, referencedColumns []string) *TableForeignKey {
 	return &TableForeignKey{
-		name: name,
-		columns: columns,
-		referencedTable: referencedTable,
+		name:              name,
+		columns:           columns,
+		referencedTable:   referencedTable,
 		referencedColumns: referencedColumns,
 	}
 }
+
+// Name returns the constraint name.
+func (fk *TableForeignKey) Name() string {
+	return fk.name
+}
+
+func (fk *TableForeignKey) Columns() []string {
+	return fk.columns
+}
+
+func (fk *TableForeignKey) ReferencedTable() string {
+	return fk.referencedTable
+}
+
+func (fk *TableForeignKey) ReferencedColumns() []string {
+	return fk.referencedColumns
+}
+
+func (fk *TableForeignKey) OnDelete() string {
+	return fk.onDelete
+}
+
+// referencesRegexp matches the value of the references tag: table(column[,column...]).
+var referencesRegexp = regexp.MustCompile(`^\s*([^()\s]+)\s*\(([^()]+)\)\s*$`)
+
+// parseReferences parses the value of the references tag into the referenced table and columns.
+func parseReferences(value string) (string, []string, error) {
+	m := referencesRegexp.FindStringSubmatch(value)
+	if m == nil {
+		return "", nil, fmt.Errorf("invalid %s tag %q, expected table(column)", TAG_REFERENCES, value)
+	}
+	columns := strings.Split(m[2], ",")
+	for i := range columns {
+		columns[i] = strings.TrimSpace(columns[i])
+	}
+	return m[1], columns, nil
+}
@@ -2,58 +2,285 @@ package aaronsql
 
 import (
 	"fmt"
-	"sort"
+	"strings"
 )
 
+// IndexColumn is a key part of an index: a column or an expression with its sort order.
+type IndexColumn struct {
+	// Column is the column name, empty for expressions
+	Column string
+	// Expression is the SQL expression of an expression index, eg: lower(email)
+	Expression string
+	// Desc sorts the key part in descending order
+	Desc bool
+	// Nulls is FIRST or LAST, empty for the default order of NULL values
+	Nulls string
+}
+
+// Asc returns the index key part of column in ascending order.
+func Asc(column string) IndexColumn {
+	return IndexColumn{Column: column}
+}
+
+// Desc returns the index key part of column in descending order.
+func Desc(column string) IndexColumn {
+	return IndexColumn{Column: column, Desc: true}
+}
+
+// Expr returns the index key part of a SQL expression, eg: Expr("lower(email)").
+func Expr(expression string) IndexColumn {
+	return IndexColumn{Expression: expression}
+}
+
+// NullsFirst sorts NULL values before the others.
+func (c IndexColumn) NullsFirst() IndexColumn {
+	c.Nulls = "FIRST"
+	return c
+}
+
+// NullsLast sorts NULL values after the others.
+func (c IndexColumn) NullsLast() IndexColumn {
+	c.Nulls = "LAST"
+	return c
+}
+
+// key returns the column name or the expression of the key part.
+func (c IndexColumn) key() string {
+	if c.Expression != "" {
+		return c.Expression
+	}
+	return c.Column
+}
+
+// sql renders the key part for db, expressions are put in parentheses.
+func (c IndexColumn) sql(db DBInterface) string {
+	s := db.QuoteIdentifier(c.Column)
+	if c.Expression != "" {
+		s = "(" + c.Expression + ")"
+	}
+	if c.Desc {
+		s += " DESC"
+	}
+	if c.Nulls != "" {
+		s += " NULLS " + c.Nulls
+	}
+	return s
+}
+
+// equal compares key parts, expressions are compared as normalized by normalizeSQLExpression.
+func (c IndexColumn) equal(other IndexColumn) bool {
+	if (c.Expression == "") != (other.Expression == "") || c.Desc != other.Desc || c.Nulls != other.Nulls {
+		return false
+	}
+	if c.Expression != "" {
+		return normalizeSQLExpression(c.Expression) == normalizeSQLExpression(other.Expression)
+	}
+	return c.Column == other.Column
+}
+
+// IndexOption configures an index added with Table.AddIndexWithOptions.
+type IndexOption func(*TableIndex)
+
+// IndexUnique makes the index unique.
+func IndexUnique() IndexOption {
+	return func(i *TableIndex) {
+		i.isUnique = true
+	}
+}
+
+// IndexWhere makes the index partial, only rows matching predicate are indexed.
+func IndexWhere(predicate string) IndexOption {
+	return func(i *TableIndex) {
+		i.where = predicate
+	}
+}
+
+// IndexInclude adds non-key columns to the index, eg: for index only scans.
+func IndexInclude(columns ...string) IndexOption {
+	return func(i *TableIndex) {
+		i.include = append(i.include, columns...)
+	}
+}
+
+// IndexType sets the index method, eg: gin, gist, hash or brin on PostgreSQL and FULLTEXT,
+// SPATIAL or HASH on MariaDB.
+func IndexType(method string) IndexOption {
+	return func(i *TableIndex) {
+		i.method = method
+	}
+}
+
+// IndexConcurrently builds the index without blocking writes.
+func IndexConcurrently() IndexOption {
+	return func(i *TableIndex) {
+		i.concurrently = true
+	}
+}
+
 type TableIndex struct {
 	name    string
-	columns []string
+	columns []IndexColumn
+	// include lists the non-key columns of the index
+	include []string
+	// where is the predicate of a partial index, empty for full indexes
+	where string
+	// method is the index method, eg: gin or FULLTEXT, empty for the default B-tree
+	method string
 
 	isUnique bool
+	// concurrently builds the index without blocking writes
+	concurrently bool
+	// invalid marks an introspected index left unusable by a failed concurrent build
+	invalid bool
 
 	table TableInterface
 }
 
+// NewTableIndex returns an index on cols, in the given order.
 func NewTableIndex(table TableInterface, name string, cols []string, unique bool) TableIndex {
-	sort.Strings(cols)
+	columns := make([]IndexColumn, len(cols))
+	for j, col := range cols {
+		columns[j] = Asc(col)
+	}
 	return TableIndex{
 		name:     name,
-		columns:  cols,
+		columns:  columns,
 		isUnique: unique,
 		table:    table,
 	}
 }
 
+// IndexLimit is the identifier limit of index names created with NewTableIndex without a name,
+// tables use the limit of their database, see DBInterface.MaxIdentifierLength.
 const IndexLimit = 64
 
+// Name returns the index name. Indexes created with NewTableIndex without a name are named
+// by DefaultNamingStrategy.
 func (i *TableIndex) Name() string {
 	if i.name != "" {
 		return i.name
 	}
-	idxName := fmt.Sprintf("idx_%s_%s", i.table.ConstructType().Name(), i.columns[0])
-	if len(idxName) > IndexLimit {
-		idxName = idxName[:IndexLimit]
+	keys := make([]string, len(i.columns))
+	for j, col := range i.columns {
+		keys[j] = col.key()
+	}
+	strategy := DefaultNamingStrategy{}
+	if i.isUnique {
+		return shortenIdentifier(strategy.UniqueName(i.table.Name(), keys), IndexLimit)
 	}
-	return idxName
+	return shortenIdentifier(strategy.IndexName(i.table.Name(), keys), IndexLimit)
 }
 
+// Columns returns the key parts of the index.
+func (i *TableIndex) Columns() []IndexColumn {
+	return i.columns
+}
+
+// Include returns the non-key columns of the index.
+func (i *TableIndex) Include() []string {
+	return i.include
+}
+
+// Where returns the predicate of a partial index, empty for full indexes.
+func (i *TableIndex) Where() string {
+	return i.where
+}
+
+// Method returns the index method, empty for the default B-tree.
+func (i *TableIndex) Method() string {
+	return i.method
+}
+
+// IsIdentical reports whether the index keys are columns in the same order, given as column
+// names or expressions.
 func (i *TableIndex) IsIdentical(columns ...string) bool {
 	if len(i.columns) != len(columns) {
 		return false
 	}
-	sort.Strings(columns)
 	for j := 0; j < len(i.columns); j++ {
-		if i.columns[j] != columns[j] {
+		if i.columns[j].key() != columns[j] {
 			return false
 		}
 	}
 	return true
 }
 
+// Equal reports whether other defines the same index: the same key parts and sort orders,
+// uniqueness, method, INCLUDE columns and partial index predicate. Names are not compared.
+func (i *TableIndex) Equal(other *TableIndex) bool {
+	if i.isUnique != other.isUnique || !sameIndexMethod(i.method, other.method) || len(i.columns) != len(other.columns) || len(i.include) != len(other.include) {
+		return false
+	}
+	for j := range i.columns {
+		if !i.columns[j].equal(other.columns[j]) {
+			return false
+		}
+	}
+	for j := range i.include {
+		if i.include[j] != other.include[j] {
+			return false
+		}
+	}
+	return normalizeSQLExpression(i.where) == normalizeSQLExpression(other.where)
+}
+
+// hasColumn reports whether a key part or INCLUDE column of the index is in columns.
+func (i *TableIndex) hasColumn(columns map[string]bool) bool {
+	for _, col := range i.columns {
+		if col.Column != "" && columns[col.Column] {
+			return true
+		}
+	}
+	for _, col := range i.include {
+		if columns[col] {
+			return true
+		}
+	}
+	return false
+}
+
+// renameColumns returns the index with the columns renamed by Sync, old names mapped to new
+// ones, replaced by their new name.
+func (i TableIndex) renameColumns(renamed map[string]string) TableIndex {
+	if len(renamed) == 0 {
+		return i
+	}
+	columns := make([]IndexColumn, len(i.columns))
+	for j, col := range i.columns {
+		if name, ok := renamed[col.Column]; ok {
+			col.Column = name
+		}
+		columns[j] = col
+	}
+	include := make([]string, len(i.include))
+	for j, col := range i.include {
+		if name, ok := renamed[col]; ok {
+			col = name
+		}
+		include[j] = col
+	}
+	i.columns, i.include = columns, include
+	return i
+}
+
+// hasOptions reports whether the index uses expressions, NULLS ordering, INCLUDE columns or a
+// predicate, see DBInterface.IsSupportIndexOptions.
+func (i *TableIndex) hasOptions() bool {
+	if i.where != "" || len(i.include) > 0 {
+		return true
+	}
+	for _, col := range i.columns {
+		if col.Expression != "" || col.Nulls != "" {
+			return true
+		}
+	}
+	return false
+}
+
 func (i *TableIndex) QuotedColumns(quoteStr string) []string {
 	ret := make([]string, len(i.columns))
 	for j := 0; j < len(ret); j++ {
-		ret[j] = fmt.Sprintf("%s%s%s", quoteStr, i.columns[j], quoteStr)
+		ret[j] = fmt.Sprintf("%s%s%s", quoteStr, i.columns[j].key(), quoteStr)
 	}
 	return ret
 }
@@ -61,3 +288,47 @@ func (i *TableIndex) QuotedColumns(quoteStr string) []string {
 func (i *TableIndex) IsUnique() bool {
 	return i.isUnique
 }
+
+// IsConcurrently reports whether the index is built without blocking writes.
+func (i *TableIndex) IsConcurrently() bool {
+	return i.concurrently
+}
+
+// IsValid reports whether the index is usable, see WithConcurrentIndexes.
+func (i *TableIndex) IsValid() bool {
+	return !i.invalid
+}
+
+// sameIndexMethod compares index methods case-insensitively, empty is the default B-tree.
+func sameIndexMethod(a, b string) bool {
+	if a == "" {
+		a = "btree"
+	}
+	if b == "" {
+		b = "btree"
+	}
+	return strings.EqualFold(a, b)
+}
+
+// splitIndexTag splits the value of an index tag at the commas outside of quotes and
+// parentheses, so predicates and expressions may contain commas.
+func splitIndexTag(value string) []string {
+	var parts []string
+	depth, start := 0, 0
+	inQuote := false
+	for j, r := range value {
+		switch {
+		case r == '\'':
+			inQuote = !inQuote
+		case inQuote:
+		case r == '(':
+			depth++
+		case r == ')':
+			depth--
+		case r == ',' && depth == 0:
+			parts = append(parts, strings.TrimSpace(value[start:j]))
+			start = j + 1
+		}
+	}
+	return append(parts, strings.TrimSpace(value[start:]))
+}
@@ -0,0 +1,241 @@
+package aaronsql
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestIndexTagOptions(t *testing.T) {
+	type account struct {
+		ID        int64     `db:"name:id;primary"`
+		Email     string    `db:"name:email;width:100;index:idx_email,expr:lower(email),where:deleted_at IS NULL"`
+		Score     int       `db:"name:score;index:idx_score,desc,nulls:last"`
+		Name      string    `db:"name:name;width:50;index:idx_score,include"`
+		DeletedAt time.Time `db:"name:deleted_at"`
+	}
+	registerTestDB(t, "index_options_test", &PostgresDataBase{DataBase: DataBase{name: PostgresDB}})
+
+	table, err := NewTableFromStructWithDB(account{}, "accounts", "index_options_test")
+	if err != nil {
+		t.Fatalf("Failed to create table from struct: %v", err)
+	}
+	indexes := make(map[string]TableIndex)
+	for _, idx := range table.Indexes() {
+		indexes[idx.Name()] = idx
+	}
+
+	email := indexes["idx_email"]
+	if cols := email.Columns(); len(cols) != 1 || cols[0].Expression != "lower(email)" {
+		t.Errorf("Expected idx_email on lower(email), got %+v", cols)
+	}
+	if email.Where() != "deleted_at IS NULL" {
+		t.Errorf("Expected idx_email to be partial, got where %q", email.Where())
+	}
+
+	score := indexes["idx_score"]
+	if cols := score.Columns(); len(cols) != 1 || cols[0] != (IndexColumn{Column: "score", Desc: true, Nulls: "LAST"}) {
+		t.Errorf("Expected idx_score on score DESC NULLS LAST, got %+v", cols)
+	}
+	if include := score.Include(); len(include) != 1 || include[0] != "name" {
+		t.Errorf("Expected idx_score to include name, got %v", include)
+	}
+}
+
+func TestAddIndexWithOptions(t *testing.T) {
+	type user struct {
+		ID        int64     `db:"name:id;primary"`
+		Email     string    `db:"name:email;width:100"`
+		Name      string    `db:"name:name;width:50"`
+		DeletedAt time.Time `db:"name:deleted_at"`
+	}
+	registerTestDB(t, "add_index_test", &PostgresDataBase{DataBase: DataBase{name: PostgresDB}})
+
+	table, err := NewTableFromStructWithDB(user{}, "users", "add_index_test")
+	if err != nil {
+		t.Fatalf("Failed to create table from struct: %v", err)
+	}
+	err = table.AddIndexWithOptions("idx_active_email", []IndexColumn{Expr("lower(email)"), Desc("id").NullsFirst()},
+		IndexUnique(), IndexWhere("deleted_at IS NULL"), IndexInclude("name"))
+	if err != nil {
+		t.Fatalf("Failed to add index: %v", err)
+	}
+	if err := table.AddIndexWithOptions("idx_active_email", []IndexColumn{Asc("name")}); err == nil {
+		t.Errorf("Expected an error for a duplicate index name")
+	}
+	if err := table.AddIndexWithOptions("idx_missing", []IndexColumn{Asc("missing")}); err == nil {
+		t.Errorf("Expected an error for an unknown column")
+	}
+	if err := table.AddIndexWithOptions("idx_missing", []IndexColumn{Asc("name")}, IndexInclude("missing")); err == nil {
+		t.Errorf("Expected an error for an unknown include column")
+	}
+
+	idx := table.Indexes()[len(table.Indexes())-1]
+	columns := make([]string, len(idx.Columns()))
+	for i, col := range idx.Columns() {
+		columns[i] = col.sql(table.db)
+	}
+	data := SQLTemplateData{
+		TableName: `"users"`,
+		IndexName: `"idx_active_email"`,
+		Columns:   strings.Join(columns, ", "),
+		Include:   strings.Join(quoteIdentifiers(table.db, idx.Include()), ", "),
+		Where:     idx.Where(),
+		Unique:    idx.IsUnique(),
+	}
+	got, err := renderSQL(table.db, CreateIndexTemplate, data)
+	if err != nil {
+		t.Fatalf("Failed to render create index template: %v", err)
+	}
+	expected := `CREATE UNIQUE INDEX "idx_active_email" ON "users" ((lower(email)), "id" DESC NULLS FIRST) INCLUDE ("name") WHERE deleted_at IS NULL;`
+	if got != expected {
+		t.Errorf("Expected %s, got %s", expected, got)
+	}
+}
+
+func TestTableIndexEqual(t *testing.T) {
+	declared := TableIndex{
+		columns:  []IndexColumn{Expr("lower(email)"), Desc("created_at")},
+		where:    "deleted_at IS NULL AND status = 'active'",
+		isUnique: true,
+	}
+	// PostgreSQL prints expressions and predicates back with casts and parentheses
+	introspected := TableIndex{
+		columns:  []IndexColumn{Expr("lower((email)::text)"), Desc("created_at")},
+		where:    "((deleted_at IS NULL) AND ((status)::text = 'active'::text))",
+		isUnique: true,
+	}
+	if !declared.Equal(&introspected) {
+		t.Errorf("Expected the introspected index to equal the declared one")
+	}
+
+	changes := []func(i *TableIndex){
+		func(i *TableIndex) { i.isUnique = false },
+		func(i *TableIndex) { i.where = "" },
+		func(i *TableIndex) { i.include = []string{"name"} },
+		func(i *TableIndex) { i.columns = []IndexColumn{Expr("lower(email)"), Asc("created_at")} },
+		func(i *TableIndex) { i.columns = []IndexColumn{Expr("lower(email)"), Desc("created_at").NullsLast()} },
+		func(i *TableIndex) { i.columns = []IndexColumn{Expr("upper(email)"), Desc("created_at")} },
+		func(i *TableIndex) { i.columns = []IndexColumn{Asc("email"), Desc("created_at")} },
+		func(i *TableIndex) { i.where = "((deleted_at IS NULL) AND ((status)::text = 'Active'::text))" },
+		func(i *TableIndex) { i.where = "((deleted_at IS NULL) OR (status)::text = 'active'::text)" },
+	}
+	for n, change := range changes {
+		changed := introspected
+		change(&changed)
+		if declared.Equal(&changed) {
+			t.Errorf("Expected change %d to make the indexes differ", n)
+		}
+	}
+}
+
+func TestPostgresIndexColumn(t *testing.T) {
+	cases := []struct {
+		column, definition string
+		option             int64
+		expected           IndexColumn
+	}{
+		{"email", "email", 0, Asc("email")},
+		{"email", "email", 1 | 2, Desc("email")},
+		{"email", "email", 1, Desc("email").NullsLast()},
+		{"email", "email", 2, Asc("email").NullsFirst()},
+		{"", "lower((email)::text)", 0, Expr("lower((email)::text)")},
+	}
+	for _, c := range cases {
+		if got := postgresIndexColumn(c.column, c.definition, c.option); got != c.expected {
+			t.Errorf("Expected %+v for option %d, got %+v", c.expected, c.option, got)
+		}
+	}
+}
+
+func TestSplitIndexTag(t *testing.T) {
+	got := splitIndexTag("idx_status, where:status IN ('a,b', 'c'), expr:coalesce(a, b)")
+	expected := []string{"idx_status", "where:status IN ('a,b', 'c')", "expr:coalesce(a, b)"}
+	if strings.Join(got, "|") != strings.Join(expected, "|") {
+		t.Errorf("Expected %q, got %q", expected, got)
+	}
+}
+
+func TestIndexTypeOption(t *testing.T) {
+	type document struct {
+		ID   int64    `db:"name:id;primary"`
+		Tags []string `db:"name:tags;index:idx_tags,type:GIN"`
+	}
+	registerTestDB(t, "index_type_test", &PostgresDataBase{DataBase: DataBase{name: PostgresDB}})
+
+	table, err := NewTableFromStructWithDB(document{}, "documents", "index_type_test")
+	if err != nil {
+		t.Fatalf("Failed to create table from struct: %v", err)
+	}
+	idx := table.Indexes()[0]
+	if idx.Method() != "gin" {
+		t.Errorf("Expected idx_tags to use gin, got %q", idx.Method())
+	}
+	if err := table.AddIndexWithOptions("idx_id", []IndexColumn{Asc("id")}, IndexType("fulltext")); err == nil {
+		t.Errorf("Expected an error for an index type PostgreSQL does not support")
+	}
+
+	type broken struct {
+		ID   int64  `db:"name:id;primary"`
+		Body string `db:"name:body;index:idx_body,type:inverted"`
+	}
+	if _, err := NewTableFromStructWithDB(broken{}, "broken", "index_type_test"); err == nil {
+		t.Errorf("Expected an error for an unknown index type tag")
+	}
+
+	btree := TableIndex{columns: []IndexColumn{Asc("id")}}
+	introspected := TableIndex{columns: []IndexColumn{Asc("id")}, method: "btree"}
+	if !btree.Equal(&introspected) {
+		t.Errorf("Expected the default method to equal btree")
+	}
+	introspected.method = "hash"
+	if btree.Equal(&introspected) {
+		t.Errorf("Expected a changed method to make the indexes differ")
+	}
+}
+
+func TestCompositeIndexTags(t *testing.T) {
+	type order struct {
+		ID         int64     `db:"name:id;primary"`
+		CustomerID int64     `db:"name:customer_id;index:idx_customer_status,priority:1;index:idx_customer"`
+		Status     string    `db:"name:status;width:20;index:idx_customer_status,priority:2;index:idx_status_created,unique"`
+		CreatedAt  time.Time `db:"name:created_at;index:idx_customer_status,priority:3;index:idx_status_created"`
+		Region     string    `db:"name:region;width:20;index:idx_region_zone,priority:2"`
+		Zone       string    `db:"name:zone;width:20;index:idx_region_zone,priority:1"`
+	}
+	registerTestDB(t, "composite_index_test", &PostgresDataBase{DataBase: DataBase{name: PostgresDB}})
+
+	table, err := NewTableFromStructWithDB(order{}, "orders", "composite_index_test")
+	if err != nil {
+		t.Fatalf("Failed to create table from struct: %v", err)
+	}
+	expected := map[string][]string{
+		"idx_customer_status": {"customer_id", "status", "created_at"},
+		"idx_customer":        {"customer_id"},
+		"idx_status_created":  {"status", "created_at"},
+		"idx_region_zone":     {"zone", "region"},
+	}
+	var names []string
+	for _, idx := range table.Indexes() {
+		names = append(names, idx.Name())
+		cols, ok := expected[idx.Name()]
+		if !ok {
+			t.Errorf("Unexpected index %s", idx.Name())
+			continue
+		}
+		if !idx.IsIdentical(cols...) {
+			t.Errorf("Expected index %s on %v, got %+v", idx.Name(), cols, idx.Columns())
+		}
+		if idx.IsUnique() != (idx.Name() == "idx_status_created") {
+			t.Errorf("Expected index %s unique=%t", idx.Name(), idx.Name() == "idx_status_created")
+		}
+	}
+	if got := strings.Join(names, ","); got != "idx_customer_status,idx_customer,idx_status_created,idx_region_zone" {
+		t.Errorf("Expected indexes in declaration order, got %s", got)
+	}
+
+	idx := NewTableIndex(table, "idx_b_a", []string{"b", "a"}, false)
+	if idx.IsIdentical("a", "b") || !idx.IsIdentical("b", "a") {
+		t.Errorf("Expected IsIdentical to respect the column order")
+	}
+}
@@ -0,0 +1,99 @@
+package aaronsql
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"time"
+)
+
+// ErrLockTimeout is returned when a database lock is not acquired within its timeout.
+var ErrLockTimeout = errors.New("lock timeout")
+
+// lockNameLimit is the maximum length of MariaDB lock names.
+const lockNameLimit = 64
+
+// WithLock makes Sync hold a database lock keyed on the schema and table while it introspects
+// and alters the table, so concurrent syncs of the same table run one after another. Waiting
+// for the lock fails with ErrLockTimeout after timeout, zero waits until the context is done.
+func WithLock(timeout time.Duration) SyncOption {
+	return func(o *syncOptions) {
+		o.lock = true
+		o.lockTimeout = timeout
+	}
+}
+
+// WithSchemaLock is like WithLock but the lock is keyed on the schema only, it serializes
+// syncs of all tables of the schema. Syncs only wait for each other when they use the same
+// lock option.
+func WithSchemaLock(timeout time.Duration) SyncOption {
+	return func(o *syncOptions) {
+		o.lock = true
+		o.lockSchema = true
+		o.lockTimeout = timeout
+	}
+}
+
+// syncLockKey returns the lock key of syncs of table in schema, an empty table locks the schema.
+func syncLockKey(schema, table string) string {
+	if table == "" {
+		return "aaronsql.sync:" + schema
+	}
+	return "aaronsql.sync:" + schema + "." + table
+}
+
+// lockKeys returns the sorted and distinct lock keys of a sync of tables, tables[i] being
+// synced in schemas[i]: one key per table with WithLock and one per schema with WithSchemaLock.
+func (o syncOptions) lockKeys(schemas, tables []string) []string {
+	seen := make(map[string]bool, len(tables))
+	var keys []string
+	for i, table := range tables {
+		if o.lockSchema {
+			table = ""
+		}
+		key := syncLockKey(schemas[i], table)
+		if !seen[key] {
+			seen[key] = true
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// advisoryLockID maps a lock key to a PostgreSQL advisory lock id.
+func advisoryLockID(key string) int64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(key))
+	return int64(h.Sum64())
+}
+
+// lockName shortens lock keys longer than the MariaDB limit by replacing their tail with a hash.
+func lockName(key string) string {
+	if len(key) <= lockNameLimit {
+		return key
+	}
+	suffix := fmt.Sprintf("#%016x", uint64(advisoryLockID(key)))
+	return key[:lockNameLimit-len(suffix)] + suffix
+}
+
+// lockContext returns ctx limited to timeout, zero keeps the deadline of ctx.
+func lockContext(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
+// discardConn closes the session of conn instead of returning it to the pool, which releases
+// session level locks which may still be held.
+func discardConn(conn *sql.Conn) {
+	_ = conn.Raw(func(interface{}) error {
+		return driver.ErrBadConn
+	})
+	_ = conn.Close()
+}
@@ -0,0 +1,59 @@
+package aaronsql
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLockNames(t *testing.T) {
+	if advisoryLockID("aaronsql.sync:app.users") != advisoryLockID("aaronsql.sync:app.users") {
+		t.Errorf("Expected advisory lock ids to be stable")
+	}
+	if advisoryLockID(syncLockKey("app", "users")) == advisoryLockID(syncLockKey("app", "orders")) {
+		t.Errorf("Expected different tables to use different advisory locks")
+	}
+	if syncLockKey("app", "") != "aaronsql.sync:app" || syncLockKey("app", "users") != "aaronsql.sync:app.users" {
+		t.Errorf("Unexpected sync lock keys")
+	}
+
+	short := syncLockKey("app", "users")
+	if lockName(short) != short {
+		t.Errorf("Expected short lock names to be kept, got %s", lockName(short))
+	}
+	long := syncLockKey("tenant_"+strings.Repeat("x", 40), "order_items_archive")
+	name := lockName(long)
+	if len(name) != lockNameLimit || !strings.HasPrefix(name, "aaronsql.sync:tenant_") {
+		t.Errorf("Expected long lock names to be shortened to %d bytes, got %s", lockNameLimit, name)
+	}
+	if name == lockName(long+"2") {
+		t.Errorf("Expected shortened lock names to stay distinct")
+	}
+}
+
+func TestLockOptions(t *testing.T) {
+	options := newSyncOptions([]SyncOption{WithLock(5 * time.Second)})
+	if !options.lock || options.lockSchema || options.lockTimeout != 5*time.Second {
+		t.Errorf("Unexpected table lock options: %+v", options)
+	}
+	options = newSyncOptions([]SyncOption{WithSchemaLock(time.Second)})
+	if !options.lock || !options.lockSchema {
+		t.Errorf("Unexpected schema lock options: %+v", options)
+	}
+}
+
+func TestLockKeys(t *testing.T) {
+	schemas := []string{"app", "app", "audit"}
+	tables := []string{"users", "orders", "events"}
+	// SyncAll takes the keys Table.Sync takes for each table, sorted
+	keys := newSyncOptions([]SyncOption{WithLock(time.Second)}).lockKeys(schemas, tables)
+	expected := []string{"aaronsql.sync:app.orders", "aaronsql.sync:app.users", "aaronsql.sync:audit.events"}
+	if strings.Join(keys, ",") != strings.Join(expected, ",") {
+		t.Errorf("Expected table lock keys %v, got %v", expected, keys)
+	}
+	keys = newSyncOptions([]SyncOption{WithSchemaLock(time.Second)}).lockKeys(schemas, tables)
+	expected = []string{"aaronsql.sync:app", "aaronsql.sync:audit"}
+	if strings.Join(keys, ",") != strings.Join(expected, ",") {
+		t.Errorf("Expected schema lock keys %v, got %v", expected, keys)
+	}
+}
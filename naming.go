@@ -0,0 +1,117 @@
+package aaronsql
+
+import (
+	"fmt"
+	"hash/fnv"
+	"strings"
+	"unicode/utf8"
+)
+
+// NamingStrategy names the indexes, unique indexes, foreign keys and checks declared without
+// a name. Columns are the column names, or the expressions of expression indexes, in order.
+// Names longer than the identifier limit of the database are shortened with a hash suffix.
+type NamingStrategy interface {
+	IndexName(table string, columns []string) string
+	UniqueName(table string, columns []string) string
+	ForeignKeyName(table string, columns []string) string
+	CheckName(table string, columns []string) string
+}
+
+// DefaultNamingStrategy names indexes idx_<table>_<columns>, unique indexes
+// uq_<table>_<columns>, foreign keys fk_<table>_<columns> and checks chk_<table>_<columns>.
+// Columns are joined with underscores, other characters than letters and digits become single
+// underscores. When the table or a column contains an underscore or other characters, the name
+// gets the hash suffix of shortenIdentifier so that eg: columns a_b, c and a, b_c or the
+// expression lower(email) and the column lower_email are named differently.
+type DefaultNamingStrategy struct{}
+
+func (DefaultNamingStrategy) IndexName(table string, columns []string) string {
+	return defaultConstraintName("idx", table, columns)
+}
+
+func (DefaultNamingStrategy) UniqueName(table string, columns []string) string {
+	return defaultConstraintName("uq", table, columns)
+}
+
+func (DefaultNamingStrategy) ForeignKeyName(table string, columns []string) string {
+	return defaultConstraintName("fk", table, columns)
+}
+
+func (DefaultNamingStrategy) CheckName(table string, columns []string) string {
+	return defaultConstraintName("chk", table, columns)
+}
+
+func defaultConstraintName(prefix, table string, columns []string) string {
+	parts := append([]string{prefix, table}, columns...)
+	ambiguous := false
+	for i, part := range parts {
+		parts[i] = strings.Trim(strings.Map(func(r rune) rune {
+			if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' {
+				return r
+			}
+			ambiguous = true
+			return '_'
+		}, part), "_")
+	}
+	name := strings.Join(parts, "_")
+	for strings.Contains(name, "__") {
+		name = strings.ReplaceAll(name, "__", "_")
+	}
+	if ambiguous {
+		name += identifierHash(strings.Join(append([]string{table}, columns...), "\x00"))
+	}
+	return name
+}
+
+// SetNamingStrategy sets the naming strategy of the tables of this database, nil restores
+// DefaultNamingStrategy. It must be called before the tables are created.
+func (d *DataBase) SetNamingStrategy(strategy NamingStrategy) {
+	d.namingStrategy = strategy
+}
+
+// NamingStrategy returns the naming strategy of the tables of this database.
+func (d *DataBase) NamingStrategy() NamingStrategy {
+	if d.namingStrategy == nil {
+		return DefaultNamingStrategy{}
+	}
+	return d.namingStrategy
+}
+
+// shortenIdentifier shortens names longer than limit bytes by replacing their tail with a
+// hash of the full name, so distinct long names stay distinct.
+func shortenIdentifier(name string, limit int) string {
+	if limit <= 0 || len(name) <= limit {
+		return name
+	}
+	suffix := identifierHash(name)
+	n := limit - len(suffix)
+	for n > 0 && !utf8.RuneStart(name[n]) {
+		n--
+	}
+	return name[:n] + suffix
+}
+
+// identifierHash returns the _<hash> suffix which keeps shortened or sanitized names distinct.
+func identifierHash(name string) string {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(name))
+	return fmt.Sprintf("_%08x", h.Sum32())
+}
+
+// identifierName shortens name to the identifier limit of the database of the table.
+func (t *Table) identifierName(name string) string {
+	return shortenIdentifier(name, t.db.MaxIdentifierLength())
+}
+
+// defaultIndexName names an index declared without a name with the naming strategy.
+func (t *Table) defaultIndexName(unique bool, columns []IndexColumn) string {
+	keys := make([]string, len(columns))
+	for i, col := range columns {
+		keys[i] = col.key()
+	}
+	strategy := t.db.GetDB().NamingStrategy()
+	if unique {
+		return t.identifierName(strategy.UniqueName(t.name, keys))
+	}
+	return t.identifierName(strategy.IndexName(t.name, keys))
+}
@@ -0,0 +1,112 @@
+package aaronsql
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+type prefixNaming struct {
+	DefaultNamingStrategy
+}
+
+func (prefixNaming) IndexName(table string, columns []string) string {
+	return "ix_" + table + "_" + strings.Join(columns, "_")
+}
+
+func TestDefaultNamingStrategy(t *testing.T) {
+	type user struct {
+		ID       int64  `db:"name:id;primary"`
+		Email    string `db:"name:email;width:100;unique"`
+		TenantID int64  `db:"name:tenant_id;index:true;references:tenants(id)"`
+		Name     string `db:"name:name;width:50;index:idx_tenant_name,priority:2"`
+		Tenant   int64  `db:"name:tenant;index:idx_tenant_name,priority:1"`
+	}
+	registerTestDB(t, "naming_test", &PostgresDataBase{DataBase: DataBase{name: PostgresDB}})
+
+	table, err := NewTableFromStructWithDB(user{}, "users", "naming_test")
+	if err != nil {
+		t.Fatalf("Failed to create table from struct: %v", err)
+	}
+	var names []string
+	for _, idx := range table.Indexes() {
+		names = append(names, idx.Name())
+	}
+	if got := strings.Join(names, ","); got != "uq_users_email,idx_users_tenant_id_9c677657,idx_tenant_name" {
+		t.Errorf("Expected strategy names for unnamed indexes, got %s", got)
+	}
+	if fks := table.ForeignKeys(); len(fks) != 1 || fks[0].Name() != "fk_users_tenant_id_9c677657" {
+		t.Errorf("Expected foreign key fk_users_tenant_id_9c677657, got %v", fks)
+	}
+
+	if !table.AddIndex(false, "name", "email") {
+		t.Fatalf("Expected the index to be added")
+	}
+	if got := table.Indexes()[len(table.Indexes())-1].Name(); got != "idx_users_name_email" {
+		t.Errorf("Expected idx_users_name_email, got %s", got)
+	}
+	if err := table.AddIndexWithOptions("", []IndexColumn{Expr("lower(email)")}, IndexUnique()); err != nil {
+		t.Fatalf("Failed to add index: %v", err)
+	}
+	if got := table.Indexes()[len(table.Indexes())-1].Name(); got != "uq_users_lower_email_b4f2867d" {
+		t.Errorf("Expected uq_users_lower_email_b4f2867d, got %s", got)
+	}
+}
+
+func TestDefaultNamingStrategyCollisions(t *testing.T) {
+	strategy := DefaultNamingStrategy{}
+	collisions := [][2][]string{
+		{{"a_b", "c"}, {"a", "b_c"}},
+		{{"lower(email)"}, {"lower_email"}},
+		{{"b", "c"}, {"b_c"}},
+	}
+	for _, c := range collisions {
+		first, second := strategy.IndexName("t", c[0]), strategy.IndexName("t", c[1])
+		if first == second {
+			t.Errorf("Expected columns %v and %v to be named differently, got %s twice", c[0], c[1], first)
+		}
+	}
+	if got := strategy.IndexName("t", []string{"a", "b"}); got != "idx_t_a_b" {
+		t.Errorf("Expected plain columns to be named without a hash, got %s", got)
+	}
+	if first, second := strategy.IndexName("a_b", []string{"c"}), strategy.IndexName("a", []string{"b", "c"}); first == second {
+		t.Errorf("Expected tables a_b and a to name their indexes differently, got %s twice", first)
+	}
+}
+
+func TestNamingStrategyLongNames(t *testing.T) {
+	type event struct {
+		ID                          int64  `db:"name:id;primary"`
+		AVeryLongColumnNameForIndex string `db:"name:a_very_long_column_name_for_the_index;width:10;index:true"`
+		AnotherLongColumnName       string `db:"name:another_long_column_name_for_the_index;width:10;index:true"`
+	}
+	postgres := &PostgresDataBase{DataBase: DataBase{name: PostgresDB}}
+	postgres.SetNamingStrategy(prefixNaming{})
+	registerTestDB(t, "long_naming_test", postgres)
+
+	table, err := NewTableFromStructWithDB(event{}, "application_audit_events", "long_naming_test")
+	if err != nil {
+		t.Fatalf("Failed to create table from struct: %v", err)
+	}
+	indexes := table.Indexes()
+	if len(indexes) != 2 {
+		t.Fatalf("Expected 2 indexes, got %d", len(indexes))
+	}
+	for _, idx := range indexes {
+		if len(idx.Name()) > postgres.MaxIdentifierLength() {
+			t.Errorf("Expected index name %s to fit %d bytes", idx.Name(), postgres.MaxIdentifierLength())
+		}
+		if !strings.HasPrefix(idx.Name(), "ix_application_audit_events_") {
+			t.Errorf("Expected the naming strategy to be used, got %s", idx.Name())
+		}
+	}
+	if indexes[0].Name() == indexes[1].Name() {
+		t.Errorf("Expected shortened names to stay distinct, got %s twice", indexes[0].Name())
+	}
+	if got := shortenIdentifier("short_name", 63); got != "short_name" {
+		t.Errorf("Expected short names to be kept, got %s", got)
+	}
+	if got := shortenIdentifier(strings.Repeat("é", 40), 63); len(got) > 63 || !utf8.ValidString(got) {
+		t.Errorf("Expected a valid shortened name within the limit, got %s", got)
+	}
+}
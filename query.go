@@ -0,0 +1,185 @@
+package aaronsql
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// defaultSearchConfig is the text search configuration of searchable columns tagged without one.
+const defaultSearchConfig = "simple"
+
+// Query selects the records of a table, eg:
+//
+//	var posts []Post
+//	err := table.Query().Search("postgres index").Limit(20).All(&posts)
+type Query struct {
+	table  *Table
+	search string
+	limit  int
+	offset int
+}
+
+// Query returns a query selecting all records of the table.
+func (t *Table) Query() *Query {
+	return &Query{table: t}
+}
+
+// Search keeps the records whose searchable columns match term and orders them by relevance,
+// best first. PostgreSQL matches the search vector with plainto_tsquery and ranks with
+// ts_rank, MariaDB uses MATCH ... AGAINST in natural language mode.
+func (q *Query) Search(term string) *Query {
+	q.search = term
+	return q
+}
+
+// Limit returns at most n records, zero returns all.
+func (q *Query) Limit(n int) *Query {
+	q.limit = n
+	return q
+}
+
+// Offset skips the first n records.
+func (q *Query) Offset(n int) *Query {
+	q.offset = n
+	return q
+}
+
+// All loads the records into dst, a pointer to a slice of structs or of pointers to structs.
+func (q *Query) All(dst interface{}) error {
+	return q.AllContext(context.Background(), dst)
+}
+
+// AllContext is All with a context, which also selects the tenant schema.
+func (q *Query) AllContext(ctx context.Context, dst interface{}) error {
+	t := q.table
+	schema, err := t.resolveSchema(ctx)
+	if err != nil {
+		return err
+	}
+	sliceValue := reflect.ValueOf(dst)
+	if sliceValue.Kind() != reflect.Ptr || sliceValue.Elem().Kind() != reflect.Slice {
+		return fmt.Errorf("expected a pointer to slice, got: %s", sliceValue.Kind().String())
+	}
+	sliceValue = sliceValue.Elem()
+	elemType := sliceValue.Type().Elem()
+	isPtr := elemType.Kind() == reflect.Ptr
+	if isPtr {
+		elemType = elemType.Elem()
+	}
+	if elemType.Kind() != reflect.Struct {
+		return fmt.Errorf("expected a slice of structs, got: %s", sliceValue.Type().String())
+	}
+
+	// Select the columns backed by a struct field
+	prototype := reflect.New(elemType).Elem()
+	var columns []ColumnInterface
+	var columnNames []string
+	for _, col := range t.columns {
+		if fieldValue, found := t.fieldByColumn(prototype, col); found && fieldValue.CanSet() {
+			columns = append(columns, col)
+			columnNames = append(columnNames, t.db.QuoteIdentifier(col.Name()))
+		}
+	}
+	if len(columns) == 0 {
+		return fmt.Errorf("no columns to select from table %s", t.name)
+	}
+
+	querySQL, args, err := q.sql(schema, strings.Join(columnNames, ", "))
+	if err != nil {
+		return err
+	}
+	rows, err := t.db.GetDB().db.QueryContext(ctx, querySQL, args...)
+	if err != nil {
+		return fmt.Errorf("failed to query table %s: %w", t.name, err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	records := reflect.MakeSlice(sliceValue.Type(), 0, 0)
+	for rows.Next() {
+		record := reflect.New(elemType)
+		targets := make([]interface{}, len(columns))
+		for i, col := range columns {
+			fieldValue, _ := t.fieldByColumn(record.Elem(), col)
+			targets[i] = col.ScanTarget(fieldValue)
+		}
+		if err := rows.Scan(targets...); err != nil {
+			return fmt.Errorf("failed to scan record of table %s: %w", t.name, err)
+		}
+		if isPtr {
+			records = reflect.Append(records, record)
+		} else {
+			records = reflect.Append(records, record.Elem())
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to query table %s: %w", t.name, err)
+	}
+	sliceValue.Set(records)
+	return nil
+}
+
+// sql renders the statement of the query selecting columns from the table in schema, with its
+// arguments.
+func (q *Query) sql(schema, columns string) (string, []interface{}, error) {
+	t := q.table
+	data := SQLTemplateData{
+		TableName: t.qualifiedName(schema),
+		Columns:   columns,
+		Limit:     q.limit,
+		Offset:    q.offset,
+	}
+	var args []interface{}
+	if q.search != "" {
+		searchColumns := t.searchColumns()
+		if len(searchColumns) == 0 {
+			return "", nil, fmt.Errorf("table %s has no searchable columns", t.name)
+		}
+		searchData := SQLTemplateData{
+			Columns:      strings.Join(quoteIdentifiers(t.db, searchColumns), ", "),
+			Values:       "?",
+			SearchConfig: quoteLiteral(t.searchConfig),
+		}
+		if t.db.Name() == PostgresDB {
+			// the term is bound once and referenced by the condition and the rank
+			searchData.Values = "$1"
+		}
+		condition, err := renderSQL(t.db, SearchTemplate, searchData)
+		if err != nil {
+			return "", nil, err
+		}
+		rank, err := renderSQL(t.db, SearchRankTemplate, searchData)
+		if err != nil {
+			return "", nil, err
+		}
+		data.Conditions = condition
+		data.OrderBy = rank + " DESC"
+		args = append(args, q.search)
+		if t.db.Name() != PostgresDB {
+			args = append(args, q.search)
+		}
+	}
+	querySQL, err := renderSQL(t.db, QueryTemplate, data)
+	if err != nil {
+		return "", nil, err
+	}
+	return querySQL, args, nil
+}
+
+// searchColumns returns the columns searched by Query.Search: the search vector column when
+// the database has one, the searchable columns otherwise.
+func (t *Table) searchColumns() []string {
+	if col := t.Column(SearchVectorColumn); col != nil && col.GeneratedExpression() != "" {
+		return []string{SearchVectorColumn}
+	}
+	var columns []string
+	for _, col := range t.columns {
+		if col.IsSearchable() {
+			columns = append(columns, col.Name())
+		}
+	}
+	return columns
+}
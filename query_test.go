@@ -0,0 +1,123 @@
+package aaronsql
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type searchPost struct {
+	ID    int64  `db:"name:id;primary"`
+	Title string `db:"name:title;width:200;searchable:english"`
+	Body  string `db:"name:body;searchable"`
+}
+
+func TestSearchablePostgres(t *testing.T) {
+	postgres := &PostgresDataBase{DataBase: DataBase{name: PostgresDB}}
+	registerTestDB(t, "search_postgres_test", postgres)
+
+	table, err := NewTableFromStructWithDB(searchPost{}, "posts", "search_postgres_test")
+	if err != nil {
+		t.Fatalf("Failed to create table from struct: %v", err)
+	}
+	if !table.Column("title").IsSearchable() || table.Column("id").IsSearchable() {
+		t.Errorf("Expected only the tagged columns to be searchable")
+	}
+	vector := table.Column(SearchVectorColumn)
+	if vector == nil {
+		t.Fatalf("Expected a %s column", SearchVectorColumn)
+	}
+	expression := `to_tsvector('english', coalesce("title", '') || ' ' || coalesce("body", ''))`
+	if vector.GeneratedExpression() != expression {
+		t.Errorf("Expected generated expression %s, got %s", expression, vector.GeneratedExpression())
+	}
+	createSQL := postgres.GetCreateTableSQL("", "posts", table.Columns(), table.createTableOptions())
+	if !strings.Contains(createSQL, `"search_vector" tsvector GENERATED ALWAYS AS (`+expression+`) STORED`) {
+		t.Errorf("Expected the generated column in %s", createSQL)
+	}
+	idx := table.Indexes()[len(table.Indexes())-1]
+	if idx.Name() != "idx_posts_search_vector_2c1275f2" || idx.Method() != "gin" || !idx.IsIdentical(SearchVectorColumn) {
+		t.Errorf("Expected a GIN index on the search vector, got %s %s %+v", idx.Name(), idx.Method(), idx.Columns())
+	}
+
+	// PostgreSQL prints the generation expression back with casts
+	introspected := `to_tsvector('english'::regconfig, (((COALESCE(title, ''::character varying))::text || ' '::text) || COALESCE(body, ''::text)))`
+	if normalizeSQLExpression(introspected) != normalizeSQLExpression(expression) {
+		t.Errorf("Expected the introspected generation expression to equal the declared one")
+	}
+
+	querySQL, args, err := table.Query().Search("fast index").Limit(20).Offset(40).sql("", `"id", "title"`)
+	if err != nil {
+		t.Fatalf("Failed to render query: %v", err)
+	}
+	expected := `SELECT "id", "title" FROM "posts" WHERE "search_vector" @@ plainto_tsquery('english', $1) ORDER BY ts_rank("search_vector", plainto_tsquery('english', $1)) DESC LIMIT 20 OFFSET 40;`
+	if querySQL != expected {
+		t.Errorf("Expected %s, got %s", expected, querySQL)
+	}
+	if !reflect.DeepEqual(args, []interface{}{"fast index"}) {
+		t.Errorf("Expected the term to be bound once, got %v", args)
+	}
+}
+
+func TestSearchableMariaDB(t *testing.T) {
+	registerTestDB(t, "search_mariadb_test", &MariaDBDataBase{DataBase: DataBase{name: MariaDB}})
+
+	table, err := NewTableFromStructWithDB(searchPost{}, "posts", "search_mariadb_test")
+	if err != nil {
+		t.Fatalf("Failed to create table from struct: %v", err)
+	}
+	if table.Column(SearchVectorColumn) != nil {
+		t.Errorf("Expected no search vector column on MariaDB")
+	}
+	idx := table.Indexes()[len(table.Indexes())-1]
+	if idx.Method() != "FULLTEXT" || !idx.IsIdentical("title", "body") {
+		t.Errorf("Expected a FULLTEXT index on title and body, got %s %+v", idx.Method(), idx.Columns())
+	}
+
+	querySQL, args, err := table.Query().Search("fast index").Offset(10).sql("", "`id`")
+	if err != nil {
+		t.Fatalf("Failed to render query: %v", err)
+	}
+	match := "MATCH (`title`, `body`) AGAINST (? IN NATURAL LANGUAGE MODE)"
+	expected := "SELECT `id` FROM `posts` WHERE " + match + " ORDER BY " + match + " DESC LIMIT 18446744073709551615 OFFSET 10;"
+	if querySQL != expected {
+		t.Errorf("Expected %s, got %s", expected, querySQL)
+	}
+	if !reflect.DeepEqual(args, []interface{}{"fast index", "fast index"}) {
+		t.Errorf("Expected the term to be bound twice, got %v", args)
+	}
+
+	if querySQL, _, _ := table.Query().sql("", "`id`"); querySQL != "SELECT `id` FROM `posts`;" {
+		t.Errorf("Expected a plain select without search, got %s", querySQL)
+	}
+}
+
+func TestSearchableErrors(t *testing.T) {
+	registerTestDB(t, "search_errors_test", &PostgresDataBase{DataBase: DataBase{name: PostgresDB}})
+
+	type count struct {
+		ID    int64 `db:"name:id;primary"`
+		Total int64 `db:"name:total;searchable"`
+	}
+	if _, err := NewTableFromStructWithDB(count{}, "counts", "search_errors_test"); err == nil {
+		t.Errorf("Expected an error for a searchable integer")
+	}
+	type mixed struct {
+		ID    int64  `db:"name:id;primary"`
+		Title string `db:"name:title;searchable:english"`
+		Body  string `db:"name:body;searchable:german"`
+	}
+	if _, err := NewTableFromStructWithDB(mixed{}, "mixed", "search_errors_test"); err == nil {
+		t.Errorf("Expected an error for different text search configurations")
+	}
+	type plain struct {
+		ID int64 `db:"name:id;primary"`
+	}
+	table, err := NewTableFromStructWithDB(plain{}, "plain", "search_errors_test")
+	if err != nil {
+		t.Fatalf("Failed to create table from struct: %v", err)
+	}
+	if _, _, err := table.Query().Search("x").sql("", `"id"`); err == nil {
+		t.Errorf("Expected an error searching a table without searchable columns")
+	}
+}
@@ -1,2 +1,191 @@
 package aaronsql
 
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ErrForeignKeyCycle is returned by SyncAll when the foreign keys of the tables form a cycle.
+var ErrForeignKeyCycle = errors.New("foreign key cycle")
+
+// SyncAll synchronizes tables of db in the order of their foreign key dependencies. The existing
+// tables are introspected once per schema, then all tables are created or updated with referenced
+// tables before the tables referencing them, and the missing foreign keys are added last.
+// References to tables which are not passed are left to the database to check.
+func SyncAll(ctx context.Context, db DBInterface, tables ...*Table) error {
+	return SyncAllWithOptions(ctx, db, tables)
+}
+
+// SyncAllWithOptions is SyncAll with sync options. With WithLock the tables, or with
+// WithSchemaLock their schemas, are locked in a fixed order before they are introspected, with
+// the same locks Table.Sync takes. Every lock holds a connection of the pool until the sync ends.
+func SyncAllWithOptions(ctx context.Context, db DBInterface, tables []*Table, opts ...SyncOption) (err error) {
+	options := newSyncOptions(opts)
+	for _, t := range tables {
+		if t.db != db {
+			return fmt.Errorf("table %s belongs to another database", t.name)
+		}
+	}
+	ordered, err := sortTablesByDependencies(tables)
+	if err != nil {
+		return err
+	}
+
+	schemas := make([]string, len(ordered))
+	names := make([]string, len(ordered))
+	refSchemas := make(map[string]string, len(ordered))
+	seenSchemas := make(map[string]bool)
+	var distinctSchemas []string
+	for i, t := range ordered {
+		schema, err := t.resolveSchema(ctx)
+		if err != nil {
+			return err
+		}
+		schemas[i] = schema
+		names[i] = t.name
+		refSchemas[t.name] = schema
+		if !seenSchemas[schema] {
+			seenSchemas[schema] = true
+			distinctSchemas = append(distinctSchemas, schema)
+		}
+	}
+	sort.Strings(distinctSchemas)
+
+	if options.lock {
+		for _, key := range options.lockKeys(schemas, names) {
+			release, err := db.AcquireLock(ctx, key, options.lockTimeout)
+			if err != nil {
+				return fmt.Errorf("failed to lock sync %s: %w", key, err)
+			}
+			defer func() {
+				err = errors.Join(err, release())
+			}()
+		}
+	}
+
+	existing := make(map[string]map[string]*Table)
+	for _, schema := range distinctSchemas {
+		if options.createSchema && schema != "" {
+			if _, err := db.GetDB().db.ExecContext(ctx, db.CreateSchemaSql(schema)); err != nil {
+				return fmt.Errorf("failed to create schema %s: %w", schema, err)
+			}
+		}
+		existTables, err := db.GetTables(schema)
+		if err != nil {
+			return fmt.Errorf("failed to get tables of schema %q: %w", schema, err)
+		}
+		existing[schema] = make(map[string]*Table, len(existTables))
+		for j := range existTables {
+			existing[schema][existTables[j].name] = &existTables[j]
+		}
+	}
+
+	for i, t := range ordered {
+		if err := t.syncTable(ctx, schemas[i], existing[schemas[i]][t.name], options); err != nil {
+			return err
+		}
+	}
+	for i, t := range ordered {
+		if err := t.syncForeignKeys(ctx, schemas[i], existing[schemas[i]][t.name], refSchemas); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sortTablesByDependencies orders tables so that every table follows the tables its foreign
+// keys reference, keeping the given order otherwise. Self references are ignored.
+func sortTablesByDependencies(tables []*Table) ([]*Table, error) {
+	byName := make(map[string]*Table, len(tables))
+	for _, t := range tables {
+		if _, ok := byName[t.name]; ok {
+			return nil, fmt.Errorf("duplicate table %s", t.name)
+		}
+		byName[t.name] = t
+	}
+
+	const (
+		visiting = iota + 1
+		visited
+	)
+	state := make(map[string]int, len(tables))
+	ordered := make([]*Table, 0, len(tables))
+	var path []string
+	var visit func(t *Table) error
+	visit = func(t *Table) error {
+		switch state[t.name] {
+		case visited:
+			return nil
+		case visiting:
+			start := len(path) - 1
+			for path[start] != t.name {
+				start--
+			}
+			cycle := append(append([]string{}, path[start:]...), t.name)
+			return fmt.Errorf("%w: %s", ErrForeignKeyCycle, strings.Join(cycle, " -> "))
+		}
+		state[t.name] = visiting
+		path = append(path, t.name)
+		for _, fk := range t.constraints {
+			dep, ok := byName[fk.referencedTable]
+			if !ok || dep == t {
+				continue
+			}
+			if err := visit(dep); err != nil {
+				return err
+			}
+		}
+		path = path[:len(path)-1]
+		state[t.name] = visited
+		ordered = append(ordered, t)
+		return nil
+	}
+	for _, t := range tables {
+		if err := visit(t); err != nil {
+			return nil, err
+		}
+	}
+	return ordered, nil
+}
+
+// syncForeignKeys adds the foreign keys of the table which existTable lacks. Referenced tables
+// are qualified with their schema in refSchemas, or with schema when they are not listed.
+func (t *Table) syncForeignKeys(ctx context.Context, schema string, existTable *Table, refSchemas map[string]string) error {
+	if !t.db.IsSupportForeignKeys() {
+		return nil
+	}
+	existing := make(map[string]bool)
+	if existTable != nil {
+		for _, fk := range existTable.constraints {
+			existing[fk.name] = true
+		}
+	}
+	for _, fk := range t.constraints {
+		if existing[fk.name] {
+			continue
+		}
+		refSchema := schema
+		if s, ok := refSchemas[fk.referencedTable]; ok {
+			refSchema = s
+		}
+		fkSQL, err := renderSQL(t.db, AddForeignKeyTemplate, SQLTemplateData{
+			Schema:            t.db.QuoteIdentifier(schema),
+			TableName:         t.qualifiedName(schema),
+			ConstraintName:    t.db.QuoteIdentifier(fk.name),
+			Columns:           strings.Join(quoteIdentifiers(t.db, fk.columns), ", "),
+			ReferencedTable:   t.db.QuoteIdentifier(refSchema, fk.referencedTable),
+			ReferencedColumns: strings.Join(quoteIdentifiers(t.db, fk.referencedColumns), ", "),
+			OnDelete:          fk.onDelete,
+		})
+		if err != nil {
+			return err
+		}
+		if _, err := t.db.GetDB().db.ExecContext(ctx, fkSQL); err != nil {
+			return fmt.Errorf("failed to add foreign key %s to table %s: %w", fk.name, t.name, err)
+		}
+	}
+	return nil
+}
@@ -1,7 +1,12 @@
 package aaronsql
 
 import (
+	"context"
 	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"strings"
 	"testing"
 	"time"
 
@@ -36,7 +41,7 @@ const (
 func forceDropTables(dbName string, tableNames ...string) {
 	if db, exists := globalDBInstances[dbName]; exists {
 		for _, tableName := range tableNames {
-			sql := db.DropTableSql(tableName)
+			sql := db.DropTableSql("", tableName)
 			if sql != "" {
 				_, _ = db.GetDB().db.Exec(sql)
 			}
@@ -300,7 +305,7 @@ func TestPostgresSyncAddColumn(t *testing.T) {
 	var count int
 	err = db.db.QueryRow(`
 		SELECT COUNT(*) FROM information_schema.columns 
-		WHERE table_name = 'test_users' AND column_name = 'lastlogin'
+		WHERE table_name = 'test_users' AND lower(column_name) = 'lastlogin'
 	`).Scan(&count)
 	if err != nil {
 		t.Fatalf("Failed to check new column existence: %v", err)
@@ -310,6 +315,64 @@ func TestPostgresSyncAddColumn(t *testing.T) {
 	}
 }
 
+func TestPostgresSyncUpgradesCaseFoldedTable(t *testing.T) {
+	db, cleanup := setupPostgresDB(t)
+	defer cleanup()
+
+	globalDBInstances["postgres_test"] = db
+
+	// test_users as created before identifiers were quoted, with case-folded column names
+	_, err := db.db.Exec(`
+		CREATE TABLE test_users (ID BIGINT NOT NULL, Name TEXT NOT NULL, Email TEXT NOT NULL, Age INTEGER,
+			IsActive BOOLEAN NOT NULL DEFAULT true, CreatedAt TIMESTAMP WITH TIME ZONE NOT NULL, PRIMARY KEY (ID))
+	`)
+	if err != nil {
+		t.Fatalf("Failed to create case-folded table: %v", err)
+	}
+
+	table, err := NewTableFromStructWithDB(TestUser{}, "test_users", "postgres_test")
+	if err != nil {
+		t.Fatalf("Failed to create table from struct: %v", err)
+	}
+	if err := table.Sync(); err != nil {
+		t.Fatalf("Failed to sync case-folded table: %v", err)
+	}
+
+	var count int
+	err = db.db.QueryRow(`
+		SELECT COUNT(*) FROM information_schema.columns
+		WHERE table_name = 'test_users' AND column_name IN ('ID', 'Name', 'Email', 'Age', 'IsActive', 'CreatedAt')
+	`).Scan(&count)
+	if err != nil {
+		t.Fatalf("Failed to check renamed columns: %v", err)
+	}
+	if count != 6 {
+		t.Errorf("Expected the 6 columns to be renamed to their declared names, got %d", count)
+	}
+
+	user := &TestUser{Name: "Ada", Email: "ada@example.com", IsActive: true, CreatedAt: time.Now()}
+	if err := table.Insert(user); err != nil {
+		t.Fatalf("Failed to insert into upgraded table: %v", err)
+	}
+	var users []TestUser
+	if err := table.Query().All(&users); err != nil || len(users) != 1 {
+		t.Fatalf("Failed to query upgraded table: %v", err)
+	}
+	loaded := &TestUser{ID: users[0].ID}
+	if err := table.Get(loaded); err != nil {
+		t.Fatalf("Failed to get from upgraded table: %v", err)
+	}
+	if loaded.Email != user.Email {
+		t.Errorf("Expected email %s, got %s", user.Email, loaded.Email)
+	}
+	if err := table.Update(loaded, func() error {
+		loaded.Name = "Ada Lovelace"
+		return nil
+	}); err != nil {
+		t.Fatalf("Failed to update upgraded table: %v", err)
+	}
+}
+
 func TestMariaDBSyncAddColumn(t *testing.T) {
 	db, cleanup := setupMariaDB(t)
 	defer cleanup()
@@ -609,3 +672,426 @@ func TestMariaDBDropTable(t *testing.T) {
 		t.Fatalf("Table still exists after drop")
 	}
 }
+
+func testConcurrentSyncWithLock(t *testing.T, dbName string) {
+	const replicas = 4
+	errs := make(chan error, replicas)
+	for i := 0; i < replicas; i++ {
+		go func() {
+			table, err := NewTableFromStructWithDB(TestProduct{}, "test_products", dbName)
+			if err != nil {
+				errs <- err
+				return
+			}
+			errs <- table.Sync(WithLock(30 * time.Second))
+		}()
+	}
+	for i := 0; i < replicas; i++ {
+		if err := <-errs; err != nil {
+			t.Errorf("Concurrent sync failed: %v", err)
+		}
+	}
+}
+
+func TestPostgresSyncConcurrentWithLock(t *testing.T) {
+	db, cleanup := setupPostgresDB(t)
+	defer cleanup()
+	globalDBInstances["postgres_test"] = db
+	forceDropTables("postgres_test", "test_products")
+
+	testConcurrentSyncWithLock(t, "postgres_test")
+}
+
+func TestMariaDBSyncConcurrentWithLock(t *testing.T) {
+	db, cleanup := setupMariaDB(t)
+	defer cleanup()
+	globalDBInstances["mariadb_test"] = db
+	forceDropTables("mariadb_test", "test_products")
+
+	testConcurrentSyncWithLock(t, "mariadb_test")
+}
+
+type fkCustomer struct {
+	ID int64 `db:"name:id;primary"`
+}
+
+type fkOrder struct {
+	ID         int64 `db:"name:id;primary"`
+	CustomerID int64 `db:"name:customer_id;references:customers(id);on_delete:CASCADE"`
+}
+
+type fkOrderItem struct {
+	ID       int64  `db:"name:id;primary"`
+	OrderID  int64  `db:"name:order_id;references:orders(id)"`
+	ParentID *int64 `db:"name:parent_id;references:order_items(id)"`
+}
+
+func TestSortTablesByDependencies(t *testing.T) {
+	registerTestDB(t, "fk_test", &PostgresDataBase{DataBase: DataBase{name: PostgresDB}})
+
+	items, err := NewTableFromStructWithDB(fkOrderItem{}, "order_items", "fk_test")
+	if err != nil {
+		t.Fatalf("Failed to create table from struct: %v", err)
+	}
+	orders, err := NewTableFromStructWithDB(fkOrder{}, "orders", "fk_test")
+	if err != nil {
+		t.Fatalf("Failed to create table from struct: %v", err)
+	}
+	customers, err := NewTableFromStructWithDB(fkCustomer{}, "customers", "fk_test")
+	if err != nil {
+		t.Fatalf("Failed to create table from struct: %v", err)
+	}
+
+	fks := orders.ForeignKeys()
+	if len(fks) != 1 || fks[0].Name() != "fk_orders_customer_id_96ada364" || fks[0].ReferencedTable() != "customers" || fks[0].OnDelete() != "CASCADE" {
+		t.Errorf("Unexpected foreign keys: %+v", fks)
+	}
+
+	ordered, err := sortTablesByDependencies([]*Table{items, orders, customers})
+	if err != nil {
+		t.Fatalf("Failed to sort tables: %v", err)
+	}
+	var names []string
+	for _, table := range ordered {
+		names = append(names, table.Name())
+	}
+	if strings.Join(names, ",") != "customers,orders,order_items" {
+		t.Errorf("Expected referenced tables first, got %v", names)
+	}
+
+	if err := customers.AddForeignKey("", []string{"id"}, "order_items", []string{"id"}); err != nil {
+		t.Fatalf("Failed to add foreign key: %v", err)
+	}
+	_, err = sortTablesByDependencies([]*Table{items, orders, customers})
+	if !errors.Is(err, ErrForeignKeyCycle) || !strings.Contains(err.Error(), "order_items -> orders -> customers -> order_items") {
+		t.Errorf("Expected a foreign key cycle error, got %v", err)
+	}
+
+	if err := orders.AddForeignKey("", []string{"missing"}, "customers", []string{"id"}); err == nil {
+		t.Errorf("Expected an error for an unknown foreign key column")
+	}
+}
+
+func TestAddForeignKeySQL(t *testing.T) {
+	postgres := &PostgresDataBase{DataBase: DataBase{name: PostgresDB}}
+	data := SQLTemplateData{
+		TableName:         postgres.QuoteIdentifier("app", "orders"),
+		ConstraintName:    postgres.QuoteIdentifier("fk_orders_customer_id"),
+		Columns:           postgres.QuoteIdentifier("customer_id"),
+		ReferencedTable:   postgres.QuoteIdentifier("app", "customers"),
+		ReferencedColumns: postgres.QuoteIdentifier("id"),
+		OnDelete:          "CASCADE",
+	}
+	got, err := renderSQL(postgres, AddForeignKeyTemplate, data)
+	expected := `ALTER TABLE "app"."orders" ADD CONSTRAINT "fk_orders_customer_id" FOREIGN KEY ("customer_id") REFERENCES "app"."customers" ("id") ON DELETE CASCADE;`
+	if err != nil || got != expected {
+		t.Errorf("Expected %s, got %s (%v)", expected, got, err)
+	}
+}
+
+// recordingConn is a database connection which records the statements executed on it instead of
+// running them, so the SQL of Sync can be tested without a database. Queries return no rows,
+// except inserts returning a key, they are recorded and return insertID as LastInsertId does.
+type recordingConn struct {
+	statements []string
+	insertID   int64
+}
+
+func (c *recordingConn) Connect(context.Context) (driver.Conn, error) {
+	return c, nil
+}
+
+func (c *recordingConn) Driver() driver.Driver {
+	return recordingDriver{c}
+}
+
+func (c *recordingConn) Prepare(query string) (driver.Stmt, error) {
+	return nil, errors.New("prepared statements are not supported")
+}
+
+func (c *recordingConn) Close() error {
+	return nil
+}
+
+func (c *recordingConn) Begin() (driver.Tx, error) {
+	return nil, errors.New("transactions are not supported")
+}
+
+func (c *recordingConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	c.statements = append(c.statements, query)
+	return recordingResult{insertID: c.insertID}, nil
+}
+
+func (c *recordingConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	if strings.HasPrefix(query, "INSERT ") {
+		c.statements = append(c.statements, query)
+		return &keyRows{key: c.insertID}, nil
+	}
+	return emptyRows{}, nil
+}
+
+type recordingResult struct {
+	insertID int64
+}
+
+func (r recordingResult) LastInsertId() (int64, error) { return r.insertID, nil }
+func (r recordingResult) RowsAffected() (int64, error) { return 1, nil }
+
+// keyRows is the single row of an insert returning the generated key.
+type keyRows struct {
+	key  int64
+	read bool
+}
+
+func (r *keyRows) Columns() []string { return []string{"id"} }
+func (r *keyRows) Close() error      { return nil }
+func (r *keyRows) Next(dest []driver.Value) error {
+	if r.read {
+		return io.EOF
+	}
+	r.read = true
+	dest[0] = r.key
+	return nil
+}
+
+type recordingDriver struct {
+	conn *recordingConn
+}
+
+func (d recordingDriver) Open(string) (driver.Conn, error) {
+	return d.conn, nil
+}
+
+type emptyRows struct{}
+
+func (emptyRows) Columns() []string              { return nil }
+func (emptyRows) Close() error                   { return nil }
+func (emptyRows) Next(dest []driver.Value) error { return io.EOF }
+
+// recordStatements registers db under dbName with a recording connection.
+func recordStatements(t *testing.T, dbName string, db DBInterface) *recordingConn {
+	conn := &recordingConn{}
+	db.GetDB().db = sql.OpenDB(conn)
+	t.Cleanup(func() {
+		_ = db.GetDB().db.Close()
+	})
+	registerTestDB(t, dbName, db)
+	return conn
+}
+
+// introspectedPostgresColumn and introspectedMariaDBColumn build columns the way getColumns
+// reads them from the database.
+func introspectedPostgresColumn(col BaseColumn, sqlType string) ColumnInterface {
+	return &PostgresColumn{BaseWidthColumn: newWidthColumnFromType(col, sqlType)}
+}
+
+func introspectedMariaDBColumn(col BaseColumn, sqlType string) ColumnInterface {
+	return &MariaDBColumn{BaseWidthColumn: newWidthColumnFromType(col, sqlType)}
+}
+
+// syncCase describes the statements Sync runs to bring the existing columns of a table to the
+// ones of model.
+type syncCase struct {
+	name     string
+	db       DBInterface
+	model    interface{}
+	existing []ColumnInterface
+	indexes  []TableIndex
+	// comment is the comment of the existing table
+	comment  string
+	expected []string
+}
+
+func runSyncCases(t *testing.T, cases []syncCase) {
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			conn := recordStatements(t, "sync_diff_test", c.db)
+			table, err := NewTableFromStructWithDB(c.model, "accounts", "sync_diff_test")
+			if err != nil {
+				t.Fatalf("Failed to create table from struct: %v", err)
+			}
+			existTable := &Table{name: "accounts", columns: c.existing, indexes: c.indexes, comment: c.comment, db: c.db, extraOptions: make(map[string]string)}
+			if err := table.syncTable(context.Background(), "", existTable, syncOptions{}); err != nil {
+				t.Fatalf("Failed to sync table: %v", err)
+			}
+			if strings.Join(conn.statements, "\n") != strings.Join(c.expected, "\n") {
+				t.Errorf("Expected statements\n%s\ngot\n%s", strings.Join(c.expected, "\n"), strings.Join(conn.statements, "\n"))
+			}
+		})
+	}
+}
+
+func TestSyncCaseFoldedColumns(t *testing.T) {
+	type account struct {
+		ID        int64      `db:"primary"`
+		LastLogin *time.Time `db:"nullable;index:idx_accounts_last_login"`
+	}
+	postgres := &PostgresDataBase{DataBase: DataBase{name: PostgresDB}}
+	runSyncCases(t, []syncCase{
+		{
+			name:  "renames columns created before identifiers were quoted",
+			db:    postgres,
+			model: account{},
+			existing: []ColumnInterface{
+				introspectedPostgresColumn(BaseColumn{name: "id", isPrimaryKey: true}, "BIGINT"),
+				introspectedPostgresColumn(BaseColumn{name: "lastlogin", isNullable: true}, "TIMESTAMP WITH TIME ZONE"),
+			},
+			// indexes follow their columns, they are not built again
+			indexes: []TableIndex{{name: "idx_accounts_last_login", columns: []IndexColumn{Asc("lastlogin")}}},
+			expected: []string{
+				`ALTER TABLE "accounts" RENAME COLUMN "id" TO "ID";`,
+				`ALTER TABLE "accounts" RENAME COLUMN "lastlogin" TO "LastLogin";`,
+			},
+		},
+		{
+			name:  "keeps quoted columns",
+			db:    postgres,
+			model: account{},
+			existing: []ColumnInterface{
+				introspectedPostgresColumn(BaseColumn{name: "ID", isPrimaryKey: true}, "BIGINT"),
+				introspectedPostgresColumn(BaseColumn{name: "LastLogin", isNullable: true}, "TIMESTAMP WITH TIME ZONE"),
+			},
+			expected: []string{
+				`CREATE INDEX IF NOT EXISTS "idx_accounts_last_login" ON "accounts" ("LastLogin");`,
+			},
+		},
+	})
+}
+
+func TestSyncClearsComments(t *testing.T) {
+	type account struct {
+		ID   int64  `db:"primary"`
+		Name string `db:"width:100"`
+	}
+	runSyncCases(t, []syncCase{
+		{
+			name:  "postgres",
+			db:    &PostgresDataBase{DataBase: DataBase{name: PostgresDB}},
+			model: account{},
+			existing: []ColumnInterface{
+				introspectedPostgresColumn(BaseColumn{name: "ID", isPrimaryKey: true}, "BIGINT"),
+				introspectedPostgresColumn(BaseColumn{name: "Name", comment: "Shown to others"}, "VARCHAR(100)"),
+			},
+			comment: "User accounts",
+			expected: []string{
+				`COMMENT ON COLUMN "accounts"."Name" IS NULL;`,
+				`COMMENT ON TABLE "accounts" IS NULL;`,
+			},
+		},
+		{
+			name:  "mariadb",
+			db:    &MariaDBDataBase{DataBase: DataBase{name: MariaDB}},
+			model: account{},
+			existing: []ColumnInterface{
+				introspectedMariaDBColumn(BaseColumn{name: "ID", isPrimaryKey: true}, "BIGINT"),
+				introspectedMariaDBColumn(BaseColumn{name: "Name", comment: "Shown to others"}, "VARCHAR(100)"),
+			},
+			comment: "User accounts",
+			expected: []string{
+				"ALTER TABLE `accounts` MODIFY COLUMN `Name` VARCHAR(100) NOT NULL COMMENT '';",
+				"ALTER TABLE `accounts` COMMENT = '';",
+			},
+		},
+	})
+}
+
+func TestSyncMariaDBColumnDefinitions(t *testing.T) {
+	type account struct {
+		ID   int64  `db:"primary;auto_increment"`
+		Name string `db:"width:100;default:'guest'"`
+	}
+	mariadb := &MariaDBDataBase{DataBase: DataBase{name: MariaDB}}
+	runSyncCases(t, []syncCase{
+		{
+			name:  "keeps the definition and comment of modified columns until the comment is cleared",
+			db:    mariadb,
+			model: account{},
+			existing: []ColumnInterface{
+				introspectedMariaDBColumn(BaseColumn{name: "ID", isPrimaryKey: true, isAutoIncrement: true}, "BIGINT"),
+				introspectedMariaDBColumn(BaseColumn{name: "Name", defaultString: "'guest'", comment: "Shown to others"}, "VARCHAR(50)"),
+			},
+			expected: []string{
+				"ALTER TABLE `accounts` MODIFY COLUMN `Name` VARCHAR(100) NOT NULL DEFAULT 'guest' COMMENT 'Shown to others';",
+				"ALTER TABLE `accounts` MODIFY COLUMN `Name` VARCHAR(100) NOT NULL DEFAULT 'guest' COMMENT '';",
+			},
+		},
+		{
+			name:  "keeps auto-increment columns when changing their type",
+			db:    mariadb,
+			model: account{},
+			existing: []ColumnInterface{
+				introspectedMariaDBColumn(BaseColumn{name: "ID", isPrimaryKey: true, isAutoIncrement: true}, "INT"),
+				introspectedMariaDBColumn(BaseColumn{name: "Name", defaultString: "'guest'"}, "VARCHAR(100)"),
+			},
+			expected: []string{
+				"ALTER TABLE `accounts` MODIFY COLUMN `ID` BIGINT NOT NULL AUTO_INCREMENT;",
+			},
+		},
+		{
+			name:  "makes columns auto-incrementing",
+			db:    mariadb,
+			model: account{},
+			existing: []ColumnInterface{
+				introspectedMariaDBColumn(BaseColumn{name: "ID", isPrimaryKey: true}, "BIGINT"),
+				introspectedMariaDBColumn(BaseColumn{name: "Name", defaultString: "'guest'"}, "VARCHAR(100)"),
+			},
+			expected: []string{
+				"ALTER TABLE `accounts` MODIFY COLUMN `ID` BIGINT NOT NULL AUTO_INCREMENT;",
+			},
+		},
+		{
+			name:  "keeps synced auto-increment columns",
+			db:    mariadb,
+			model: account{},
+			existing: []ColumnInterface{
+				introspectedMariaDBColumn(BaseColumn{name: "ID", isPrimaryKey: true, isAutoIncrement: true}, "BIGINT"),
+				introspectedMariaDBColumn(BaseColumn{name: "Name", defaultString: "'guest'"}, "VARCHAR(100)"),
+			},
+		},
+	})
+}
+
+func TestSyncPostgresAutoIncrement(t *testing.T) {
+	type account struct {
+		ID     int64 `db:"primary;auto_increment;auto_increment_offset:1000"`
+		Number int32 `db:"serial"`
+	}
+	postgres := &PostgresDataBase{DataBase: DataBase{name: PostgresDB}}
+	number := introspectedPostgresColumn(BaseColumn{name: "Number", isAutoIncrement: true, autoIncrementOffset: 7}, "INTEGER")
+	runSyncCases(t, []syncCase{
+		{
+			name:  "makes columns identity columns",
+			db:    postgres,
+			model: account{},
+			existing: []ColumnInterface{
+				introspectedPostgresColumn(BaseColumn{name: "ID", isPrimaryKey: true}, "BIGINT"),
+				number,
+			},
+			expected: []string{
+				`ALTER TABLE "accounts" ALTER COLUMN "ID" ADD GENERATED BY DEFAULT AS IDENTITY (START WITH 1000);`,
+			},
+		},
+		{
+			// introspected columns report their counter as offset, it is not reset
+			name:  "keeps identity and serial columns",
+			db:    postgres,
+			model: account{},
+			existing: []ColumnInterface{
+				introspectedPostgresColumn(BaseColumn{name: "ID", isPrimaryKey: true, isAutoIncrement: true, autoIncrementOffset: 1500}, "BIGINT"),
+				number,
+			},
+		},
+		{
+			name:  "changes the type of identity columns",
+			db:    postgres,
+			model: account{},
+			existing: []ColumnInterface{
+				introspectedPostgresColumn(BaseColumn{name: "ID", isPrimaryKey: true, isAutoIncrement: true}, "INTEGER"),
+				number,
+			},
+			expected: []string{
+				`ALTER TABLE "accounts" ALTER COLUMN "ID" SET DATA TYPE BIGINT;`,
+			},
+		},
+	})
+}
@@ -1,42 +1,76 @@
 package aaronsql
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"reflect"
+	"regexp"
+	"sort"
 	"strconv"
 	"strings"
+	"time"
 )
 
 // TableInterface respresents the interface for table operations in the database.
 type TableInterface interface {
 	// Insert inserts a new record into the table.
 	Insert(dst interface{}) error
+	InsertContext(ctx context.Context, dst interface{}) error
 	Update(dst interface{}, updateFunc func() error) error
+	UpdateContext(ctx context.Context, dst interface{}, updateFunc func() error) error
+	// Get loads the record identified by the primary key fields of dst into dst.
+	Get(dst interface{}) error
+	GetContext(ctx context.Context, dst interface{}) error
 
 	ConstructType() reflect.Type
 	Column(name string) ColumnInterface
 	Name() string
+	// Schema returns the schema of the table, empty for the default schema of the connection.
+	Schema() string
 	Columns() []ColumnInterface
 	PrimaryColumns() []ColumnInterface
 	Indexes() []TableIndex
+	ForeignKeys() []TableForeignKey
 	Instance() *Table
 	DropForeignKeySql() string
 	AddIndex(unique bool, cols ...string) bool
+	AddForeignKey(name string, columns []string, referencedTable string, referencedColumns []string) error
 
 	DataBase() *DataBase
 	Drop() error
 	GetExtra() map[string]string
 	SetExtra(kvdata map[string]string)
 
-	Sync() error
+	Sync(opts ...SyncOption) error
+	SyncContext(ctx context.Context, opts ...SyncOption) error
+	// SyncAllTenants synchronizes the table in the schema of every tenant of the database.
+	SyncAllTenants(ctx context.Context, opts ...SyncOption) (*TenantSyncReport, error)
 }
 
 type Table struct {
 	structType  reflect.Type
+	schema      string
 	name        string
 	columns     []ColumnInterface
 	indexes     []TableIndex
 	constraints []TableForeignKey
+	checks      []TableCheck
+	// comment is the table comment read by data catalogs, see WithComment
+	comment string
+
+	// strictTags rejects unknown, malformed and conflicting tags
+	strictTags bool
+
+	// fieldPaths maps column names to the index path of the struct field holding the value,
+	// fields of embedded structs have paths longer than one.
+	fieldPaths map[string][]int
+	// searchConfig is the text search configuration of the searchable columns, see Query.Search
+	searchConfig string
+
+	// indexTags holds the values of the index tags of every column in tag order, a field may
+	// declare several indexes
+	indexTags map[string][]string
 
 	extraOptions map[string]string
 
@@ -47,8 +81,48 @@ func (t *Table) Name() string {
 	return t.name
 }
 
+// Schema returns the schema of the table, the default schema of the database when none is set.
+func (t *Table) Schema() string {
+	if t.schema != "" {
+		return t.schema
+	}
+	return t.db.GetDB().Schema()
+}
+
+// resolveSchema returns the schema the operations of ctx target. A schema set on the table
+// takes precedence over the tenant of ctx, which takes precedence over the database schema.
+func (t *Table) resolveSchema(ctx context.Context) (string, error) {
+	if t.schema != "" {
+		return t.schema, nil
+	}
+	if resolver := t.db.GetDB().tenantResolver; resolver != nil {
+		schema, err := resolver.ResolveTenant(ctx)
+		if err != nil {
+			return "", fmt.Errorf("failed to resolve tenant for table %s: %w", t.name, err)
+		}
+		if schema != "" {
+			return schema, nil
+		}
+	}
+	return t.db.GetDB().Schema(), nil
+}
+
+// qualifiedName returns the quoted table name, qualified with schema if it is not empty.
+func (t *Table) qualifiedName(schema string) string {
+	return t.db.QuoteIdentifier(schema, t.name)
+}
+
 // Insert inserts a new record into the table.
 func (t *Table) Insert(dst interface{}) error {
+	return t.InsertContext(context.Background(), dst)
+}
+
+// InsertContext inserts a new record into the table of the schema resolved from ctx.
+func (t *Table) InsertContext(ctx context.Context, dst interface{}) error {
+	schema, err := t.resolveSchema(ctx)
+	if err != nil {
+		return err
+	}
 	if !t.db.CanInsert() {
 		return fmt.Errorf("insert operation is not supported for database: %s", t.db.Name())
 	}
@@ -67,42 +141,29 @@ func (t *Table) Insert(dst interface{}) error {
 	var placeholders []string
 	var values []interface{}
 	placeholderIndex := 1
+	// keyField receives the value generated for the auto-increment column keyCol
+	var keyCol ColumnInterface
+	var keyField reflect.Value
 
 	for _, col := range t.columns {
-		// Skip auto-increment columns for insert
-		if col.IsAutoIncrement() {
+		// Skip auto-increment and generated columns for insert
+		if col.IsAutoIncrement() || col.GeneratedExpression() != "" {
+			if field, found := t.fieldByColumn(reflectValue, col); found && col.IsAutoIncrement() && keyCol == nil && field.CanSet() {
+				keyCol, keyField = col, field
+			}
 			continue
 		}
 
 		// Get field value by column name
-		fieldValue := reflectValue.FieldByName(col.Name())
-		if !fieldValue.IsValid() {
-			// Try to find field by struct tag name
-			found := false
-			for i := 0; i < reflectValue.NumField(); i++ {
-				field := reflectValue.Type().Field(i)
-				tagStr := field.Tag.Get(defaultModelDBTagKey)
-				if tagStr != "" {
-					tags := parseTagString(tagStr)
-					if nameTag, ok := tags[TAG_NAME]; ok && nameTag == col.Name() {
-						fieldValue = reflectValue.Field(i)
-						found = true
-						break
-					}
-				}
-				// If no name tag, check if field name matches column name
-				if !found && field.Name == col.Name() {
-					fieldValue = reflectValue.Field(i)
-					found = true
-					break
-				}
-			}
-			if !found {
-				continue // Skip if field not found
-			}
+		fieldValue, found := t.fieldByColumn(reflectValue, col)
+		if !found {
+			continue // Skip if field not found
+		}
+		if err := validateEnumValue(col, fieldValue); err != nil {
+			return err
 		}
 
-		columnNames = append(columnNames, col.Name())
+		columnNames = append(columnNames, t.db.QuoteIdentifier(col.Name()))
 
 		// Handle different database placeholder styles
 		if t.db.Name() == PostgresDB {
@@ -122,20 +183,72 @@ func (t *Table) Insert(dst interface{}) error {
 	}
 
 	// Build and execute INSERT SQL
-	insertSQL := t.db.InsertSqlTemplate()
-	insertSQL = strings.ReplaceAll(insertSQL, "{{.TableName}}", t.name)
-	insertSQL = strings.ReplaceAll(insertSQL, "{{.Columns}}", strings.Join(columnNames, ", "))
-	insertSQL = strings.ReplaceAll(insertSQL, "{{.Values}}", strings.Join(placeholders, ", "))
+	data := SQLTemplateData{
+		TableName: t.qualifiedName(schema),
+		Columns:   strings.Join(columnNames, ", "),
+		Values:    strings.Join(placeholders, ", "),
+	}
+	if keyCol != nil && t.db.CanInsertReturning() {
+		data.Returning = t.db.QuoteIdentifier(keyCol.Name())
+	}
+	insertSQL, err := renderSQL(t.db, InsertTemplate, data)
+	if err != nil {
+		return err
+	}
 
-	_, err := t.db.GetDB().db.Exec(insertSQL, values...)
+	// The generated key is written back into dst
+	if data.Returning != "" {
+		if err := t.db.GetDB().db.QueryRowContext(ctx, insertSQL, values...).Scan(keyCol.ScanTarget(keyField)); err != nil {
+			return fmt.Errorf("failed to insert into table %s: %w", t.name, err)
+		}
+		return nil
+	}
+	result, err := t.db.GetDB().db.ExecContext(ctx, insertSQL, values...)
 	if err != nil {
 		return fmt.Errorf("failed to insert into table %s: %w", t.name, err)
 	}
+	if keyCol != nil {
+		id, err := result.LastInsertId()
+		if err != nil {
+			return fmt.Errorf("failed to get the generated key of table %s: %w", t.name, err)
+		}
+		if err := setGeneratedKey(keyField, id); err != nil {
+			return fmt.Errorf("failed to set the generated key of table %s: %w", t.name, err)
+		}
+	}
+
+	return nil
+}
 
+// setGeneratedKey stores the key generated for an auto-increment column in its integer field,
+// pointer fields are allocated as needed.
+func setGeneratedKey(field reflect.Value, id int64) error {
+	if field.Kind() == reflect.Ptr {
+		field.Set(reflect.New(field.Type().Elem()))
+		field = field.Elem()
+	}
+	switch field.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		field.SetInt(id)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		field.SetUint(uint64(id))
+	default:
+		return fmt.Errorf("unsupported key field type: %s", field.Type())
+	}
 	return nil
 }
 
 func (t *Table) Update(dst interface{}, updateFunc func() error) error {
+	return t.UpdateContext(context.Background(), dst, updateFunc)
+}
+
+// UpdateContext updates the record identified by the primary key fields of dst in the table of
+// the schema resolved from ctx.
+func (t *Table) UpdateContext(ctx context.Context, dst interface{}, updateFunc func() error) error {
+	schema, err := t.resolveSchema(ctx)
+	if err != nil {
+		return err
+	}
 	if !t.db.CanUpdate() {
 		return fmt.Errorf("update operation is not supported for database: %s", t.db.Name())
 	}
@@ -170,44 +283,25 @@ func (t *Table) Update(dst interface{}, updateFunc func() error) error {
 
 	// Build SET clauses for non-primary key columns
 	for _, col := range t.columns {
-		if col.IsPrimaryKey() || col.IsAutoIncrement() {
-			continue // Skip primary keys and auto-increment columns in SET clause
+		if col.IsPrimaryKey() || col.IsAutoIncrement() || col.GeneratedExpression() != "" {
+			continue // Skip primary keys, auto-increment and generated columns in SET clause
 		}
 
 		// Get field value by column name
-		fieldValue := reflectValue.FieldByName(col.Name())
-		if !fieldValue.IsValid() {
-			// Try to find field by struct tag name
-			found := false
-			for i := 0; i < reflectValue.NumField(); i++ {
-				field := reflectValue.Type().Field(i)
-				tagStr := field.Tag.Get(defaultModelDBTagKey)
-				if tagStr != "" {
-					tags := parseTagString(tagStr)
-					if nameTag, ok := tags[TAG_NAME]; ok && nameTag == col.Name() {
-						fieldValue = reflectValue.Field(i)
-						found = true
-						break
-					}
-				}
-				// If no name tag, check if field name matches column name
-				if !found && field.Name == col.Name() {
-					fieldValue = reflectValue.Field(i)
-					found = true
-					break
-				}
-			}
-			if !found {
-				continue // Skip if field not found
-			}
+		fieldValue, found := t.fieldByColumn(reflectValue, col)
+		if !found {
+			continue // Skip if field not found
+		}
+		if err := validateEnumValue(col, fieldValue); err != nil {
+			return err
 		}
 
 		// Handle different database placeholder styles
 		if t.db.Name() == PostgresDB {
-			updateClauses = append(updateClauses, fmt.Sprintf("%s = $%d", col.Name(), placeholderIndex))
+			updateClauses = append(updateClauses, fmt.Sprintf("%s = $%d", t.db.QuoteIdentifier(col.Name()), placeholderIndex))
 			placeholderIndex++
 		} else {
-			updateClauses = append(updateClauses, fmt.Sprintf("%s = ?", col.Name()))
+			updateClauses = append(updateClauses, fmt.Sprintf("%s = ?", t.db.QuoteIdentifier(col.Name())))
 		}
 
 		// Convert value using column's conversion method
@@ -218,39 +312,17 @@ func (t *Table) Update(dst interface{}, updateFunc func() error) error {
 	// Build WHERE clause using primary key columns
 	for _, col := range primaryCols {
 		// Get field value by column name
-		fieldValue := reflectValue.FieldByName(col.Name())
-		if !fieldValue.IsValid() {
-			// Try to find field by struct tag name
-			found := false
-			for i := 0; i < reflectValue.NumField(); i++ {
-				field := reflectValue.Type().Field(i)
-				tagStr := field.Tag.Get(defaultModelDBTagKey)
-				if tagStr != "" {
-					tags := parseTagString(tagStr)
-					if nameTag, ok := tags[TAG_NAME]; ok && nameTag == col.Name() {
-						fieldValue = reflectValue.Field(i)
-						found = true
-						break
-					}
-				}
-				// If no name tag, check if field name matches column name
-				if !found && field.Name == col.Name() {
-					fieldValue = reflectValue.Field(i)
-					found = true
-					break
-				}
-			}
-			if !found {
-				return fmt.Errorf("primary key field %s not found in struct", col.Name())
-			}
+		fieldValue, found := t.fieldByColumn(reflectValue, col)
+		if !found {
+			return fmt.Errorf("primary key field %s not found in struct", col.Name())
 		}
 
 		// Handle different database placeholder styles
 		if t.db.Name() == PostgresDB {
-			whereConditions = append(whereConditions, fmt.Sprintf("%s = $%d", col.Name(), placeholderIndex))
+			whereConditions = append(whereConditions, fmt.Sprintf("%s = $%d", t.db.QuoteIdentifier(col.Name()), placeholderIndex))
 			placeholderIndex++
 		} else {
-			whereConditions = append(whereConditions, fmt.Sprintf("%s = ?", col.Name()))
+			whereConditions = append(whereConditions, fmt.Sprintf("%s = ?", t.db.QuoteIdentifier(col.Name())))
 		}
 
 		// Convert value using column's conversion method
@@ -263,12 +335,15 @@ func (t *Table) Update(dst interface{}, updateFunc func() error) error {
 	}
 
 	// Build and execute UPDATE SQL
-	updateSQL := t.db.UpdateSqlTemplate()
-	updateSQL = strings.ReplaceAll(updateSQL, "{{.TableName}}", t.name)
-	updateSQL = strings.ReplaceAll(updateSQL, "{{.Updates}}", strings.Join(updateClauses, ", "))
-	updateSQL = strings.ReplaceAll(updateSQL, "{{.Conditions}}", strings.Join(whereConditions, " AND "))
-
-	result, err := t.db.GetDB().db.Exec(updateSQL, values...)
+	updateSQL, err := renderSQL(t.db, UpdateTemplate, SQLTemplateData{
+		TableName:  t.qualifiedName(schema),
+		Updates:    strings.Join(updateClauses, ", "),
+		Conditions: strings.Join(whereConditions, " AND "),
+	})
+	if err != nil {
+		return err
+	}
+	result, err := t.db.GetDB().db.ExecContext(ctx, updateSQL, values...)
 	if err != nil {
 		return fmt.Errorf("failed to update table %s: %w", t.name, err)
 	}
@@ -287,6 +362,71 @@ func (t *Table) Update(dst interface{}, updateFunc func() error) error {
 	return nil
 }
 
+// Get loads the record identified by the primary key fields of dst into dst.
+func (t *Table) Get(dst interface{}) error {
+	return t.GetContext(context.Background(), dst)
+}
+
+// GetContext loads the record identified by the primary key fields of dst from the table of
+// the schema resolved from ctx.
+func (t *Table) GetContext(ctx context.Context, dst interface{}) error {
+	schema, err := t.resolveSchema(ctx)
+	if err != nil {
+		return err
+	}
+	reflectValue := reflect.ValueOf(dst)
+	if reflectValue.Kind() != reflect.Ptr || reflectValue.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("expected a pointer to struct, got: %s", reflectValue.Kind().String())
+	}
+	reflectValue = reflectValue.Elem()
+
+	primaryCols := t.PrimaryColumns()
+	if len(primaryCols) == 0 {
+		return fmt.Errorf("no primary key columns found for get operation")
+	}
+
+	// Build WHERE clause using primary key columns
+	var whereConditions []string
+	var values []interface{}
+	for i, col := range primaryCols {
+		fieldValue, found := t.fieldByColumn(reflectValue, col)
+		if !found {
+			return fmt.Errorf("primary key field %s not found in struct", col.Name())
+		}
+		if t.db.Name() == PostgresDB {
+			whereConditions = append(whereConditions, fmt.Sprintf("%s = $%d", t.db.QuoteIdentifier(col.Name()), i+1))
+		} else {
+			whereConditions = append(whereConditions, fmt.Sprintf("%s = ?", t.db.QuoteIdentifier(col.Name())))
+		}
+		values = append(values, col.ConvertFromValueToSQL(fieldValue.Interface()))
+	}
+
+	// Collect the scan targets of the columns backed by a struct field
+	var columnNames []string
+	var targets []interface{}
+	for _, col := range t.columns {
+		fieldValue, found := t.fieldByColumn(reflectValue, col)
+		if !found || !fieldValue.CanSet() {
+			continue
+		}
+		columnNames = append(columnNames, t.db.QuoteIdentifier(col.Name()))
+		targets = append(targets, col.ScanTarget(fieldValue))
+	}
+
+	selectSQL, err := renderSQL(t.db, SelectTemplate, SQLTemplateData{
+		TableName:  t.qualifiedName(schema),
+		Columns:    strings.Join(columnNames, ", "),
+		Conditions: strings.Join(whereConditions, " AND "),
+	})
+	if err != nil {
+		return err
+	}
+	if err := t.db.GetDB().db.QueryRowContext(ctx, selectSQL, values...).Scan(targets...); err != nil {
+		return fmt.Errorf("failed to get record from table %s: %w", t.name, err)
+	}
+	return nil
+}
+
 func (t *Table) ConstructType() reflect.Type {
 	return t.structType
 }
@@ -318,6 +458,10 @@ func (t *Table) Indexes() []TableIndex {
 	return t.indexes
 }
 
+func (t *Table) ForeignKeys() []TableForeignKey {
+	return t.constraints
+}
+
 func (t *Table) Instance() *Table {
 	return t
 }
@@ -326,189 +470,475 @@ func (t *Table) DropForeignKeySql() string {
 	return ""
 }
 
+// SyncOption configures Table.Sync.
+type SyncOption func(*syncOptions)
+
+type syncOptions struct {
+	createSchema bool
+	// parallelism is the number of tenants SyncAllTenants synchronizes at the same time
+	parallelism int
+
+	// lock holds a database lock during the sync, keyed on the schema only with lockSchema
+	lock        bool
+	lockSchema  bool
+	lockTimeout time.Duration
+
+	// concurrentIndexes builds all indexes without blocking writes
+	concurrentIndexes bool
+}
+
+// WithCreateSchema makes Sync create the schema of the table if it does not exist.
+func WithCreateSchema() SyncOption {
+	return func(o *syncOptions) {
+		o.createSchema = true
+	}
+}
+
+func newSyncOptions(opts []SyncOption) syncOptions {
+	options := syncOptions{parallelism: 1}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	return options
+}
+
+// WithConcurrentIndexes makes Sync build and rebuild all indexes without blocking writes to the
+// table: CREATE INDEX CONCURRENTLY on PostgreSQL and ALGORITHM=INPLACE LOCK=NONE on MariaDB.
+// Single indexes can opt in with the concurrently index option, eg: db:"index:idx_x,concurrently".
+func WithConcurrentIndexes() SyncOption {
+	return func(o *syncOptions) {
+		o.concurrentIndexes = true
+	}
+}
+
 // Sync synchronizes the table structure by Table.Only do the create or update operation, non destructive.
-func (t *Table) Sync() error {
-	existTable, err := t.db.GetTableDDL(t.name)
+func (t *Table) Sync(opts ...SyncOption) error {
+	return t.SyncContext(context.Background(), opts...)
+}
+
+// SyncContext synchronizes the table structure in the schema resolved from ctx.
+func (t *Table) SyncContext(ctx context.Context, opts ...SyncOption) error {
+	schema, err := t.resolveSchema(ctx)
+	if err != nil {
+		return err
+	}
+	return t.syncSchema(ctx, schema, newSyncOptions(opts))
+}
+
+// syncSchema synchronizes the table structure in schema. With a lock the table is introspected
+// only once the lock is held, so changes made by a concurrent sync are seen.
+func (t *Table) syncSchema(ctx context.Context, schema string, options syncOptions) (err error) {
+	if options.lock {
+		key := options.lockKeys([]string{schema}, []string{t.name})[0]
+		release, err := t.db.AcquireLock(ctx, key, options.lockTimeout)
+		if err != nil {
+			return fmt.Errorf("failed to lock sync of table %s: %w", t.name, err)
+		}
+		defer func() {
+			err = errors.Join(err, release())
+		}()
+	}
+	if options.createSchema && schema != "" {
+		if _, err := t.db.GetDB().db.ExecContext(ctx, t.db.CreateSchemaSql(schema)); err != nil {
+			return fmt.Errorf("failed to create schema %s: %w", schema, err)
+		}
+	}
+
+	existTable, err := t.db.GetTableDDL(schema, t.name)
 	if err != nil {
 		return fmt.Errorf("failed to get DDL for table %s: %w", t.name, err)
 	}
+	if err := t.syncTable(ctx, schema, existTable, options); err != nil {
+		return err
+	}
+	return t.syncForeignKeys(ctx, schema, existTable, nil)
+}
+
+// syncTable creates the enum types of the table and then the table in schema when existTable is
+// nil, otherwise it adds and updates the columns, comments, checks and indexes which differ
+// from existTable. Foreign keys are left to syncForeignKeys.
+func (t *Table) syncTable(ctx context.Context, schema string, existTable *Table, options syncOptions) error {
+	if err := t.syncEnums(ctx, schema); err != nil {
+		return err
+	}
 	if existTable == nil {
 		// Table does not exist, create it
-		createSQL := t.db.GetCreateTableSQL(t.name, t.columns)
+		createSQL := t.db.GetCreateTableSQL(schema, t.name, t.columns, t.createTableOptions())
 		if createSQL == "" {
 			return fmt.Errorf("failed to generate CREATE TABLE SQL for table %s", t.name)
 		}
 
 		// Execute the CREATE TABLE statement
-		_, err := t.db.GetDB().db.Exec(createSQL)
+		_, err := t.db.GetDB().db.ExecContext(ctx, createSQL)
 		if err != nil {
 			return fmt.Errorf("failed to create table %s: %w", t.name, err)
 		}
 
 		// Create indexes if any
-		createIndexSQLTemplate := t.db.CreateIndexSqlTemplate()
 		for _, index := range t.indexes {
-			var indexSQL string
-			if index.IsUnique() {
-				// For unique indexes, we need to use CREATE UNIQUE INDEX
-				indexSQL = strings.ReplaceAll(createIndexSQLTemplate, "CREATE INDEX", "CREATE UNIQUE INDEX")
-			} else {
-				indexSQL = createIndexSQLTemplate
-			}
-			indexSQL = strings.ReplaceAll(indexSQL, "{{.IndexName}}", index.Name())
-			indexSQL = strings.ReplaceAll(indexSQL, "{{.TableName}}", t.name)
-			indexSQL = strings.ReplaceAll(indexSQL, "{{.Columns}}", strings.Join(index.columns, ", "))
-			if indexSQL != "" {
-				_, err := t.db.GetDB().db.Exec(indexSQL)
-				if err != nil {
-					return fmt.Errorf("failed to create index %s for table %s: %w", index.Name(), t.name, err)
-				}
+			if err := t.createIndex(ctx, schema, index, options); err != nil {
+				return err
 			}
 		}
-	} else {
-		// Table exists, check for column differences and add missing columns
-		existingCols := existTable.Columns()
-		existingColNames := make(map[string]bool)
+		return nil
+	}
+
+	// Table exists, check for column differences and add missing columns
+	existingCols := existTable.Columns()
+
+	// Add missing columns and update existing ones if they differ
+	droppedColumns := make(map[string]bool)
+	renamedColumns := make(map[string]string)
+	for _, newCol := range t.columns {
+		var existingCol ColumnInterface
+
+		// Find existing column (case-insensitive for PostgreSQL, case-sensitive for others)
 		for _, col := range existingCols {
-			existingColNames[col.Name()] = true
-		}
-
-		// Add missing columns and update existing ones if they differ
-		for _, newCol := range t.columns {
-			colExists := false
-			var existingCol ColumnInterface
-
-			// Find existing column (case-insensitive for PostgreSQL, case-sensitive for others)
-			for _, col := range existingCols {
-				if t.db.Name() == PostgresDB {
-					// PostgreSQL is case-insensitive, compare lowercase
-					if strings.EqualFold(col.Name(), newCol.Name()) {
-						colExists = true
-						existingCol = col
-						break
-					}
-				} else {
-					// Other databases (MariaDB) are case-sensitive
-					if col.Name() == newCol.Name() {
-						colExists = true
-						existingCol = col
-						break
-					}
+			if t.db.Name() == PostgresDB {
+				// Columns created before identifiers were quoted are case-folded, compare
+				// case-insensitively and rename them below
+				if strings.EqualFold(col.Name(), newCol.Name()) {
+					existingCol = col
+					break
 				}
+			} else if col.Name() == newCol.Name() {
+				// Other databases (MariaDB) are case-sensitive
+				existingCol = col
+				break
 			}
+		}
 
-			if !colExists {
-				// Column doesn't exist, add it
-				colSQL := t.db.CreateColumnSqlTemplate()
-				colSQL = strings.ReplaceAll(colSQL, "{{.ColumnName}}", newCol.Name())
-				colSQL = strings.ReplaceAll(colSQL, "{{.ColumnType}}", newCol.Type())
-				colSQL = strings.ReplaceAll(colSQL, "{{.TableName}}", t.name)
-				if colSQL != "" {
-					_, err := t.db.GetDB().db.Exec(colSQL)
-					if err != nil {
-						return fmt.Errorf("failed to add column %s to table %s: %w", newCol.Name(), t.name, err)
-					}
-				}
-			} else {
-				// Column exists, check if it needs to be updated
-				if existingCol != nil {
-					// Compare column definitions
-					if existingCol.Type() != newCol.Type() ||
-						existingCol.Nullable() != newCol.Nullable() ||
-						existingCol.Default() != newCol.Default() {
-						// Column definition differs, update it
-						updateSQL := t.db.UpdateColumnSqlTemplate()
-						updateSQL = strings.ReplaceAll(updateSQL, "{{.ColumnName}}", newCol.Name())
-						updateSQL = strings.ReplaceAll(updateSQL, "{{.ColumnType}}", newCol.Type())
-						updateSQL = strings.ReplaceAll(updateSQL, "{{.TableName}}", t.name)
-
-						if updateSQL != "" {
-							_, err := t.db.GetDB().db.Exec(updateSQL)
-							if err != nil {
-								return fmt.Errorf("failed to update column %s in table %s: %w", newCol.Name(), t.name, err)
-							}
-						}
-					}
-				}
-			} // Create missing indexes and update existing ones if they differ
-			existingIndexes := existTable.Indexes()
-			existingIndexMap := make(map[string]TableIndex)
-			for _, idx := range existingIndexes {
-				existingIndexMap[idx.Name()] = idx
+		if existingCol == nil {
+			// Column doesn't exist, add it
+			colSQL, err := renderSQL(t.db, CreateColumnTemplate, SQLTemplateData{
+				TableName:  t.qualifiedName(schema),
+				ColumnName: t.db.QuoteIdentifier(newCol.Name()),
+				ColumnType: columnTypeSQL(t.db, schema, newCol),
+				Comment:    commentLiteral(newCol.Comment()),
+			})
+			if err != nil {
+				return err
+			}
+			if _, err := t.db.GetDB().db.ExecContext(ctx, colSQL); err != nil {
+				return fmt.Errorf("failed to add column %s to table %s: %w", newCol.Name(), t.name, err)
 			}
+			continue
+		}
 
-			for _, newIndex := range t.indexes {
-				if existingIdx, exists := existingIndexMap[newIndex.Name()]; !exists {
-					// Index doesn't exist, create it
-					var indexSQL string
-					if newIndex.IsUnique() {
-						// For unique indexes, we need to use CREATE UNIQUE INDEX
-						indexSQL = strings.ReplaceAll(t.db.CreateIndexSqlTemplate(), "CREATE INDEX", "CREATE UNIQUE INDEX")
-					} else {
-						indexSQL = t.db.CreateIndexSqlTemplate()
-					}
-					indexSQL = strings.ReplaceAll(indexSQL, "{{.IndexName}}", newIndex.Name())
-					indexSQL = strings.ReplaceAll(indexSQL, "{{.TableName}}", t.name)
-					indexSQL = strings.ReplaceAll(indexSQL, "{{.Columns}}", strings.Join(newIndex.columns, ", "))
-					if indexSQL != "" {
-						_, err := t.db.GetDB().db.Exec(indexSQL)
-						if err != nil {
-							return fmt.Errorf("failed to create index %s for table %s: %w", newIndex.Name(), t.name, err)
-						}
-					}
-				} else {
-					// Index exists, check if it matches the current definition
-					if !existingIdx.IsIdentical(newIndex.columns...) || existingIdx.isUnique != newIndex.isUnique {
-						// Index definition differs, drop and recreate it
-						dropIndexSQL := t.db.DropIndexSqlTemplate()
-						dropIndexSQL = strings.ReplaceAll(dropIndexSQL, "{{.IndexName}}", existingIdx.Name())
-						dropIndexSQL = strings.ReplaceAll(dropIndexSQL, "{{.TableName}}", t.name)
-
-						if dropIndexSQL != "" {
-							_, err := t.db.GetDB().db.Exec(dropIndexSQL)
-							if err != nil {
-								return fmt.Errorf("failed to drop index %s for table %s: %w", existingIdx.Name(), t.name, err)
-							}
-						}
-
-						// Recreate the index with new definition
-						var createIndexSQL string
-						if newIndex.IsUnique() {
-							// For unique indexes, we need to use CREATE UNIQUE INDEX
-							createIndexSQL = strings.ReplaceAll(t.db.CreateIndexSqlTemplate(), "CREATE INDEX", "CREATE UNIQUE INDEX")
-						} else {
-							createIndexSQL = t.db.CreateIndexSqlTemplate()
-						}
-						createIndexSQL = strings.ReplaceAll(createIndexSQL, "{{.IndexName}}", newIndex.Name())
-						createIndexSQL = strings.ReplaceAll(createIndexSQL, "{{.TableName}}", t.name)
-						createIndexSQL = strings.ReplaceAll(createIndexSQL, "{{.Columns}}", strings.Join(newIndex.columns, ", "))
-
-						if createIndexSQL != "" {
-							_, err := t.db.GetDB().db.Exec(createIndexSQL)
-							if err != nil {
-								return fmt.Errorf("failed to recreate index %s for table %s: %w", newIndex.Name(), t.name, err)
-							}
-						}
-					}
-				}
+		// Generated columns cannot be altered, drop and add them again when their expression
+		// changed. Indexes on them are dropped with them and created again below.
+		if newCol.GeneratedExpression() != "" &&
+			normalizeSQLExpression(existingCol.GeneratedExpression()) != normalizeSQLExpression(newCol.GeneratedExpression()) {
+			if err := t.recreateColumn(ctx, schema, existingCol, newCol); err != nil {
+				return err
+			}
+			droppedColumns[existingCol.Name()] = true
+			continue
+		}
+
+		// Rename case-folded columns to their declared name, which Insert, Update and Get quote
+		if existingCol.Name() != newCol.Name() {
+			renameSQL, err := renderSQL(t.db, RenameColumnTemplate, SQLTemplateData{
+				TableName:     t.qualifiedName(schema),
+				ColumnName:    t.db.QuoteIdentifier(existingCol.Name()),
+				NewColumnName: t.db.QuoteIdentifier(newCol.Name()),
+			})
+			if err != nil {
+				return err
+			}
+			if _, err := t.db.GetDB().db.ExecContext(ctx, renameSQL); err != nil {
+				return fmt.Errorf("failed to rename column %s of table %s to %s: %w", existingCol.Name(), t.name, newCol.Name(), err)
+			}
+			renamedColumns[existingCol.Name()] = newCol.Name()
+		}
+
+		// Existing columns are made auto-incrementing in place, on PostgreSQL they become
+		// identity columns even when tagged serial
+		if newCol.IsAutoIncrement() && !existingCol.IsAutoIncrement() {
+			autoIncrementSQL, err := renderSQL(t.db, AddAutoIncrementTemplate, SQLTemplateData{
+				TableName:  t.qualifiedName(schema),
+				ColumnName: t.db.QuoteIdentifier(newCol.Name()),
+				ColumnType: newCol.Type(),
+				Definition: t.db.ColumnDefinitionSql(schema, newCol),
+				Comment:    commentLiteral(existingCol.Comment()),
+				Start:      newCol.AutoIncrementOffset(),
+			})
+			if err != nil {
+				return err
+			}
+			if _, err := t.db.GetDB().db.ExecContext(ctx, autoIncrementSQL); err != nil {
+				return fmt.Errorf("failed to make column %s of table %s auto-incrementing: %w", newCol.Name(), t.name, err)
+			}
+		}
+
+		// Column exists, compare column definitions and update the parts which differ
+		typeChanged := !sameSQLType(existingCol.Type(), newCol.Type()) || !sameCollation(existingCol, newCol)
+		nullableChanged := existingCol.Nullable() != newCol.Nullable()
+		defaultChanged := !sameDefault(existingCol.Default(), newCol.Default())
+		updated := typeChanged || nullableChanged || defaultChanged
+		if updated {
+			updateSQL, err := renderSQL(t.db, UpdateColumnTemplate, SQLTemplateData{
+				TableName:       t.qualifiedName(schema),
+				ColumnName:      t.db.QuoteIdentifier(newCol.Name()),
+				ColumnType:      columnType(t.db, schema, newCol),
+				Definition:      t.db.ColumnDefinitionSql(schema, newCol),
+				Comment:         commentLiteral(existingCol.Comment()),
+				TypeChanged:     typeChanged,
+				NullableChanged: nullableChanged,
+				DefaultChanged:  defaultChanged,
+				Nullable:        newCol.Nullable(),
+				Default:         newCol.Default(),
+			})
+			if err != nil {
+				return err
+			}
+			if _, err := t.db.GetDB().db.ExecContext(ctx, updateSQL); err != nil {
+				return fmt.Errorf("failed to update column %s in table %s: %w", newCol.Name(), t.name, err)
 			}
 		}
+
+		// Comments of columns declared without one are cleared
+		if existingCol.Comment() != newCol.Comment() {
+			commentSQL, err := renderSQL(t.db, ColumnCommentTemplate, SQLTemplateData{
+				TableName:  t.qualifiedName(schema),
+				ColumnName: t.db.QuoteIdentifier(newCol.Name()),
+				Definition: t.db.ColumnDefinitionSql(schema, newCol),
+				Comment:    commentLiteral(newCol.Comment()),
+			})
+			if err != nil {
+				return err
+			}
+			if _, err := t.db.GetDB().db.ExecContext(ctx, commentSQL); err != nil {
+				return fmt.Errorf("failed to set comment of column %s in table %s: %w", newCol.Name(), t.name, err)
+			}
+		}
+	}
+
+	// Tables of databases without table level defaults are introspected without them
+	charset, collation := t.extraOptions[EXTRA_CHARSET], t.extraOptions[EXTRA_COLLATE]
+	existingCharset, existingCollation := existTable.extraOptions[EXTRA_CHARSET], existTable.extraOptions[EXTRA_COLLATE]
+	if charset != "" && existingCharset != "" && !strings.EqualFold(charset, existingCharset) ||
+		collation != "" && existingCollation != "" && !strings.EqualFold(collation, existingCollation) {
+		charsetSQL, err := renderSQL(t.db, TableCharsetTemplate, SQLTemplateData{
+			TableName: t.qualifiedName(schema),
+			Charset:   charset,
+			Collation: collation,
+		})
+		if err != nil {
+			return err
+		}
+		if _, err := t.db.GetDB().db.ExecContext(ctx, charsetSQL); err != nil {
+			return fmt.Errorf("failed to change character set of table %s: %w", t.name, err)
+		}
 	}
 
+	if existTable.comment != t.comment {
+		commentSQL, err := renderSQL(t.db, TableCommentTemplate, SQLTemplateData{
+			TableName: t.qualifiedName(schema),
+			Comment:   commentLiteral(t.comment),
+		})
+		if err != nil {
+			return err
+		}
+		if _, err := t.db.GetDB().db.ExecContext(ctx, commentSQL); err != nil {
+			return fmt.Errorf("failed to set comment of table %s: %w", t.name, err)
+		}
+	}
+
+	if err := t.syncChecks(ctx, schema, existTable); err != nil {
+		return err
+	}
+
+	// Create missing indexes and update existing ones if they differ
+	existingIndexMap := make(map[string]TableIndex)
+	for _, idx := range existTable.Indexes() {
+		if !idx.hasColumn(droppedColumns) {
+			existingIndexMap[idx.Name()] = idx.renameColumns(renamedColumns)
+		}
+	}
+	for _, newIndex := range t.indexes {
+		existingIdx, exists := existingIndexMap[newIndex.Name()]
+		if exists && existingIdx.IsValid() && existingIdx.Equal(&newIndex) {
+			continue
+		}
+		if exists {
+			// Index definition differs or is left invalid by a failed concurrent build, drop and recreate it
+			if err := t.dropIndex(ctx, schema, existingIdx, newIndex.concurrently || options.concurrentIndexes); err != nil {
+				return err
+			}
+		}
+		if err := t.createIndex(ctx, schema, newIndex, options); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
+// createTableOptions returns the table level parts of the CREATE TABLE statement of the table.
+func (t *Table) createTableOptions() CreateTableOptions {
+	return CreateTableOptions{
+		Checks:    t.checks,
+		Comment:   t.comment,
+		Charset:   t.extraOptions[EXTRA_CHARSET],
+		Collation: t.extraOptions[EXTRA_COLLATE],
+	}
+}
+
+// recreateColumn drops existingCol and adds newCol in its place.
+func (t *Table) recreateColumn(ctx context.Context, schema string, existingCol, newCol ColumnInterface) error {
+	dropSQL, err := renderSQL(t.db, DropColumnTemplate, SQLTemplateData{
+		TableName:  t.qualifiedName(schema),
+		ColumnName: t.db.QuoteIdentifier(existingCol.Name()),
+	})
+	if err != nil {
+		return err
+	}
+	if _, err := t.db.GetDB().db.ExecContext(ctx, dropSQL); err != nil {
+		return fmt.Errorf("failed to drop column %s from table %s: %w", existingCol.Name(), t.name, err)
+	}
+	colSQL, err := renderSQL(t.db, CreateColumnTemplate, SQLTemplateData{
+		TableName:  t.qualifiedName(schema),
+		ColumnName: t.db.QuoteIdentifier(newCol.Name()),
+		ColumnType: columnTypeSQL(t.db, schema, newCol),
+		Comment:    commentLiteral(newCol.Comment()),
+	})
+	if err != nil {
+		return err
+	}
+	if _, err := t.db.GetDB().db.ExecContext(ctx, colSQL); err != nil {
+		return fmt.Errorf("failed to add column %s to table %s: %w", newCol.Name(), t.name, err)
+	}
+	return nil
+}
+
+// createIndex executes the CREATE INDEX statement of index. Concurrent indexes are built without
+// blocking writes, such statements must not run inside a transaction.
+func (t *Table) createIndex(ctx context.Context, schema string, index TableIndex, options syncOptions) error {
+	if index.hasOptions() && !t.db.IsSupportIndexOptions() {
+		return fmt.Errorf("index %s for table %s uses expressions, NULLS order, INCLUDE or WHERE which database %s does not support", index.Name(), t.name, t.db.Name())
+	}
+	method := ""
+	if index.method != "" {
+		var err error
+		if method, err = t.db.IndexMethod(index.method); err != nil {
+			return fmt.Errorf("index %s for table %s: %w", index.Name(), t.name, err)
+		}
+	}
+	columns := make([]string, len(index.columns))
+	for i, col := range index.columns {
+		columns[i] = col.sql(t.db)
+	}
+	indexSQL, err := renderSQL(t.db, CreateIndexTemplate, SQLTemplateData{
+		TableName:    t.qualifiedName(schema),
+		IndexName:    t.db.QuoteIdentifier(index.Name()),
+		Columns:      strings.Join(columns, ", "),
+		Include:      strings.Join(quoteIdentifiers(t.db, index.include), ", "),
+		Where:        index.where,
+		Method:       method,
+		Unique:       index.IsUnique(),
+		IfNotExists:  true,
+		Concurrently: index.concurrently || options.concurrentIndexes,
+	})
+	if err != nil {
+		return err
+	}
+	if _, err := t.db.GetDB().db.ExecContext(ctx, indexSQL); err != nil {
+		return fmt.Errorf("failed to create index %s for table %s: %w", index.Name(), t.name, err)
+	}
+	return nil
+}
+
+// dropIndex executes the DROP INDEX statement of index.
+func (t *Table) dropIndex(ctx context.Context, schema string, index TableIndex, concurrently bool) error {
+	dropSQL, err := renderSQL(t.db, DropIndexTemplate, SQLTemplateData{
+		Schema:       t.db.QuoteIdentifier(schema),
+		TableName:    t.qualifiedName(schema),
+		IndexName:    t.db.QuoteIdentifier(index.Name()),
+		Concurrently: concurrently,
+	})
+	if err != nil {
+		return err
+	}
+	if _, err := t.db.GetDB().db.ExecContext(ctx, dropSQL); err != nil {
+		return fmt.Errorf("failed to drop index %s for table %s: %w", index.Name(), t.name, err)
+	}
+	return nil
+}
+
+// sameCollation compares the character set and collation declared for col with the ones of the
+// existing column, undeclared ones are the defaults of the table and always equal. PostgreSQL
+// columns are introspected without a character set.
+func sameCollation(existingCol, col ColumnInterface) bool {
+	return (col.Charset() == "" || existingCol.Charset() == "" || strings.EqualFold(existingCol.Charset(), col.Charset())) &&
+		(col.Collation() == "" || strings.EqualFold(existingCol.Collation(), col.Collation()))
+}
+
+// sameDefault compares column defaults the way databases print them back: PostgreSQL adds casts
+// and quotes negative numbers, MariaDB reports NULL for nullable columns without a default and 1
+// for true.
+func sameDefault(a, b string) bool {
+	return normalizeDefault(a) == normalizeDefault(b)
+}
+
+// defaultCastPattern matches a trailing cast such as ::integer or ::character varying(20)[].
+var defaultCastPattern = regexp.MustCompile(`::[a-z][a-z0-9_ ]*(\([0-9, ]*\))?(\[\])?$`)
+
+func normalizeDefault(s string) string {
+	s = strings.TrimSpace(s)
+	for loc := defaultCastPattern.FindStringIndex(s); loc != nil; loc = defaultCastPattern.FindStringIndex(s) {
+		s = s[:loc[0]]
+	}
+	if len(s) > 2 && s[0] == '\'' && s[len(s)-1] == '\'' {
+		if _, err := strconv.ParseFloat(s[1:len(s)-1], 64); err == nil {
+			s = s[1 : len(s)-1]
+		}
+	}
+	if strings.Contains(s, "'") {
+		// string literals are compared as written
+		return s
+	}
+	switch s = strings.ToLower(s); s {
+	case "null":
+		return ""
+	case "true":
+		return "1"
+	case "false":
+		return "0"
+	}
+	return s
+}
+
+// sameSQLType compares column types case-insensitively, ignoring redundant whitespace.
+func sameSQLType(a, b string) bool {
+	normalize := func(s string) string {
+		s = strings.Join(strings.Fields(s), " ")
+		s = strings.ReplaceAll(s, ", ", ",")
+		return strings.ReplaceAll(s, " (", "(")
+	}
+	return strings.EqualFold(normalize(a), normalize(b))
+}
+
 func (t *Table) DataBase() *DataBase {
 	return t.db.GetDB()
 }
 
+// Drop drops the table.
 func (t *Table) Drop() error {
-	dropSQL := t.db.DropTableSql(t.name)
+	return t.DropContext(context.Background())
+}
+
+// DropContext drops the table of the schema resolved from ctx.
+func (t *Table) DropContext(ctx context.Context) error {
+	schema, err := t.resolveSchema(ctx)
+	if err != nil {
+		return err
+	}
+	dropSQL := t.db.DropTableSql(schema, t.name)
 	if dropSQL == "" {
 		return fmt.Errorf("drop table SQL not supported for database: %s", t.db.Name())
 	}
 
-	_, err := t.db.GetDB().db.Exec(dropSQL)
+	_, err = t.db.GetDB().db.ExecContext(ctx, dropSQL)
 	if err != nil {
 		return fmt.Errorf("failed to drop table %s: %w", t.name, err)
 	}
@@ -538,7 +968,46 @@ func (t *Table) SetExtra(kvdata map[string]string) {
 	}
 }
 
-func NewTableFromStructWithDB(s interface{}, name string, dbName string) (*Table, error) {
+// Table options set with Table.SetExtra.
+const (
+	// EXTRA_CHARSET is the default character set of the columns of the table, MariaDB only
+	EXTRA_CHARSET = "charset"
+	// EXTRA_COLLATE is the default collation of the columns of the table, MariaDB only
+	EXTRA_COLLATE = "collate"
+)
+
+// TableOption configures a table built by NewTableFromStructWithDB.
+type TableOption func(*Table)
+
+// WithSchema places the table in schema instead of the default schema of the database. The
+// schema is a PostgreSQL schema or a MariaDB database.
+func WithSchema(schema string) TableOption {
+	return func(t *Table) {
+		t.schema = schema
+	}
+}
+
+// WithComment sets the comment of the table.
+func WithComment(comment string) TableOption {
+	return func(t *Table) {
+		t.comment = comment
+	}
+}
+
+// Comment returns the comment of the table, empty for none.
+func (t *Table) Comment() string {
+	return t.comment
+}
+
+// WithStrictTags makes NewTableFromStructWithDB fail on unknown, malformed or conflicting tags
+// instead of ignoring them.
+func WithStrictTags() TableOption {
+	return func(t *Table) {
+		t.strictTags = true
+	}
+}
+
+func NewTableFromStructWithDB(s interface{}, name string, dbName string, opts ...TableOption) (*Table, error) {
 	dbRefer := globalDBInstances[dbName]
 	if dbRefer == nil {
 		return nil, fmt.Errorf("database instance for %s not found", dbName)
@@ -547,42 +1016,154 @@ func NewTableFromStructWithDB(s interface{}, name string, dbName string) (*Table
 	if reflectType.Kind() != reflect.Struct {
 		return nil, fmt.Errorf("expected a struct type, got: %s", reflectType.Kind().String())
 	}
-	// get s tags
-	cols := make([]ColumnInterface, 0)
-	for i := 0; i < reflectType.NumField(); i++ {
-		field := reflectType.Field(i)
-		tagStr := field.Tag.Get(defaultModelDBTagKey)
-		if tagStr == "" {
-			continue
-		}
-		tags := parseTagString(tagStr)
-		if _, ok := tags[TAG_IGNORE]; ok {
-			continue // skip fields with ignore tag
-		}
-		// Create a new column based on the field information
-		col, err := globalDBInstances[dbName].GetColumnDefinitionByType(field.Type, field.Name, tags, field.Type.Kind() == reflect.Ptr)
-		if err != nil {
-			return nil, fmt.Errorf("failed to create column for field %s: %w", field.Name, err)
-		}
-		cols = append(cols, col)
-	}
 
 	table := &Table{
-		structType:   reflect.TypeOf(s),
+		structType:   reflectType,
 		name:         name,
-		columns:      cols,
+		columns:      make([]ColumnInterface, 0),
+		fieldPaths:   make(map[string][]int),
+		indexTags:    make(map[string][]string),
 		extraOptions: make(map[string]string),
 		db:           dbRefer,
 	}
+	for _, opt := range opts {
+		opt(table)
+	}
+	// get s tags
+	if err := table.collectColumns(reflectType, "", nil); err != nil {
+		return nil, err
+	}
 	if err := table.constructIndex(); err != nil {
 		return nil, fmt.Errorf("failed to construct indexes for table %s: %w", name, err)
 	}
+	if err := table.constructSearch(); err != nil {
+		return nil, fmt.Errorf("failed to construct search for table %s: %w", name, err)
+	}
 	if err := table.constructConstraints(); err != nil {
 		return nil, fmt.Errorf("failed to construct constraints for table %s: %w", name, err)
 	}
+	if err := table.constructChecks(); err != nil {
+		return nil, fmt.Errorf("failed to construct checks for table %s: %w", name, err)
+	}
 	return table, nil
 }
 
+// collectColumns walks the fields of structType and appends a column for every tagged field.
+// Anonymous embedded structs are flattened into the parent unless they are stored as a single
+// value through driver.Valuer, sql.Scanner or JSON. Named struct fields tagged with embed are
+// flattened too, with the optional prefix tag prepended to their column names.
+func (t *Table) collectColumns(structType reflect.Type, prefix string, parentIndex []int) error {
+	var tagErrs []error
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		index := make([]int, len(parentIndex)+1)
+		copy(index, parentIndex)
+		index[len(parentIndex)] = i
+
+		tagStr := field.Tag.Get(defaultModelDBTagKey)
+		tags := parseTagString(tagStr)
+		if t.strictTags && tagStr != "" {
+			if _, err := parseTagEntries(tagStr); err != nil {
+				tagErrs = append(tagErrs, fmt.Errorf("field %s: %w", field.Name, err))
+				continue
+			}
+			if err := validateTags(field, tags); err != nil {
+				tagErrs = append(tagErrs, err)
+				continue
+			}
+		}
+		if tagFlag(tags, TAG_IGNORE) {
+			continue // skip fields with ignore tag
+		}
+
+		isEmbed := tagFlag(tags, TAG_EMBED)
+		embedType, ok := embeddedStructType(field.Type)
+		if ok && (isEmbed || field.Anonymous && !isCustomValueType(embedType) && !isJSONType(embedType, tags)) {
+			if err := t.collectColumns(embedType, prefix+tags[TAG_PREFIX], index); err != nil {
+				tagErrs = append(tagErrs, err)
+			}
+			continue
+		} else if isEmbed {
+			return fmt.Errorf("field %s is tagged with %s but is not a struct", field.Name, TAG_EMBED)
+		}
+
+		if tagStr == "" || !field.IsExported() {
+			continue
+		}
+		columnName := prefix + field.Name
+		if name, ok := tags[TAG_NAME]; ok && prefix != "" {
+			tags[TAG_NAME] = prefix + name
+		}
+		// Create a new column based on the field information
+		col, err := t.db.GetColumnDefinitionByType(field.Type, columnName, tags, field.Type.Kind() == reflect.Ptr)
+		if err != nil {
+			err = fmt.Errorf("failed to create column for field %s: %w", field.Name, err)
+			if t.strictTags {
+				// strict mode reports the problems of all fields at once
+				tagErrs = append(tagErrs, err)
+				continue
+			}
+			return err
+		}
+		if _, exists := t.fieldPaths[col.Name()]; exists {
+			return fmt.Errorf("duplicate column %s for field %s", col.Name(), field.Name)
+		}
+		t.fieldPaths[col.Name()] = index
+		entries, _ := parseTagEntries(tagStr)
+		for _, entry := range entries {
+			if entry.key == TAG_INDEX {
+				t.indexTags[col.Name()] = append(t.indexTags[col.Name()], entry.value)
+			}
+		}
+		t.columns = append(t.columns, col)
+	}
+	return errors.Join(tagErrs...)
+}
+
+// embeddedStructType returns the struct type that can be flattened into columns, time.Time is
+// treated as a scalar value and never flattened.
+func embeddedStructType(fieldType reflect.Type) (reflect.Type, bool) {
+	if fieldType.Kind() == reflect.Ptr {
+		fieldType = fieldType.Elem()
+	}
+	if fieldType.Kind() != reflect.Struct || fieldType == reflect.TypeOf(time.Time{}) {
+		return nil, false
+	}
+	return fieldType, true
+}
+
+// fieldByColumn returns the struct field holding the value of col. It returns false when the
+// field does not exist or is reached through a nil embedded pointer.
+func (t *Table) fieldByColumn(reflectValue reflect.Value, col ColumnInterface) (reflect.Value, bool) {
+	if path, ok := t.fieldPaths[col.Name()]; ok && reflectValue.Type() == t.structType {
+		fieldValue, err := reflectValue.FieldByIndexErr(path)
+		if err != nil {
+			return reflect.Value{}, false
+		}
+		return fieldValue, true
+	}
+	fieldValue := reflectValue.FieldByName(col.Name())
+	if fieldValue.IsValid() {
+		return fieldValue, true
+	}
+	// Try to find field by struct tag name
+	for i := 0; i < reflectValue.NumField(); i++ {
+		field := reflectValue.Type().Field(i)
+		tagStr := field.Tag.Get(defaultModelDBTagKey)
+		if tagStr != "" {
+			tags := parseTagString(tagStr)
+			if nameTag, ok := tags[TAG_NAME]; ok && nameTag == col.Name() {
+				return reflectValue.Field(i), true
+			}
+		}
+		// If no name tag, check if field name matches column name
+		if field.Name == col.Name() {
+			return reflectValue.Field(i), true
+		}
+	}
+	return reflect.Value{}, false
+}
+
 func (table *Table) addIndexWithName(name string, unique bool, cols ...string) bool {
 	for i := 0; i < len(table.indexes); i++ {
 		if table.indexes[i].IsIdentical(cols...) {
@@ -590,76 +1171,296 @@ func (table *Table) addIndexWithName(name string, unique bool, cols ...string) b
 		}
 	}
 	idx := NewTableIndex(table, name, cols, unique)
+	if name == "" {
+		idx.name = table.defaultIndexName(unique, idx.columns)
+	} else {
+		idx.name = table.identifierName(name)
+	}
 	table.indexes = append(table.indexes, idx)
 	return true
 }
 
+// AddIndex adds an index on cols unless one exists, it is named by the naming strategy.
 func (ts *Table) AddIndex(unique bool, cols ...string) bool {
 	return ts.addIndexWithName("", unique, cols...)
 }
 
+// AddIndexWithOptions adds the index name on columns, which are built with Asc, Desc and Expr,
+// configured by opts, eg:
+//
+//	table.AddIndexWithOptions("idx_active_email", []IndexColumn{Expr("lower(email)")},
+//		IndexUnique(), IndexWhere("deleted_at IS NULL"))
+//
+// Columns and INCLUDE columns must be columns of the table, an empty name is set by the naming
+// strategy.
+func (ts *Table) AddIndexWithOptions(name string, columns []IndexColumn, opts ...IndexOption) error {
+	if len(columns) == 0 {
+		return fmt.Errorf("index %s of table %s has no columns", name, ts.name)
+	}
+	idx := TableIndex{name: name, columns: columns, table: ts}
+	for _, opt := range opts {
+		opt(&idx)
+	}
+	if name == "" {
+		idx.name = ts.defaultIndexName(idx.isUnique, idx.columns)
+	} else {
+		idx.name = ts.identifierName(name)
+	}
+	for _, col := range idx.columns {
+		if col.Expression == "" && ts.Column(col.Column) == nil {
+			return fmt.Errorf("index column %s not found in table %s", col.Column, ts.name)
+		}
+		if col.Nulls != "" && col.Nulls != "FIRST" && col.Nulls != "LAST" {
+			return fmt.Errorf("invalid NULLS order %q of index %s in table %s", col.Nulls, name, ts.name)
+		}
+	}
+	if idx.method != "" {
+		if _, err := ts.db.IndexMethod(idx.method); err != nil {
+			return fmt.Errorf("index %s of table %s: %w", name, ts.name, err)
+		}
+	}
+	for _, col := range idx.include {
+		if ts.Column(col) == nil {
+			return fmt.Errorf("index column %s not found in table %s", col, ts.name)
+		}
+	}
+	for i := range ts.indexes {
+		if ts.indexes[i].Name() == idx.Name() {
+			return fmt.Errorf("duplicate index %s in table %s", idx.Name(), ts.name)
+		}
+	}
+	ts.indexes = append(ts.indexes, idx)
+	return nil
+}
+
 // constructIndex constructs the indexes for the table based on the columns tags.
 // eg: db:"index:idx_name,unique".if two columns have the same index name, it's a composite index.
-// composite index will use priority to determine the index order. eg: db:"index:idx_name,priority:1".
-// support multiple indexes on the same column, but must with different index type. eg: db:"index:idx_name,priority:1;index:idx_name2,unique".
+// composite index columns are ordered by priority, lower first, then by field order. eg: db:"index:idx_name,priority:1".
+// support multiple indexes on the same column. eg: db:"index:idx_name,priority:1;index:idx_name2,unique".
+// the concurrently option builds the index without blocking writes. eg: db:"index:idx_name,concurrently".
+// desc and nulls:first|last set the sort order of the column, expr indexes an expression instead
+// of the column, where makes the index partial and include adds the column as a non-key column.
+// eg: db:"index:idx_email,expr:lower(email),where:deleted_at IS NULL".
+// type sets the index method. eg: db:"index:idx_tags,type:gin".
 func (t *Table) constructIndex() error {
 	indexs := make([]TableIndex, 0)
-	indexNameAndColsAndPriority := make(map[string]map[string]int)
+	// named indexes in the order they are first declared, with the priorities of their columns
+	var named []*TableIndex
+	priorities := make(map[*TableIndex][]int)
+	byName := make(map[string]*TableIndex)
 	for _, col := range t.columns {
-		tags := col.GetStructTags()
-		if indexTag, ok := tags[TAG_INDEX]; ok {
+		for _, indexTag := range t.indexTags[col.Name()] {
+			if enabled, err := strconv.ParseBool(indexTag); err == nil {
+				if !enabled {
+					continue
+				}
+				indexTag = "" // index:true declares an index with the default name
+			}
 			// If the index tag is present, we need to parse it
-			indexParts := strings.Split(indexTag, ",")
 			idxName := ""
 			priority := 0
-			for _, part := range indexParts {
-				if strings.HasPrefix(part, "priority:") {
+			unique := false
+			concurrently := false
+			include := false
+			where := ""
+			method := ""
+			part := Asc(col.Name())
+			for _, indexPart := range splitIndexTag(indexTag) {
+				key, value, hasValue := strings.Cut(indexPart, ":")
+				key = strings.TrimSpace(key)
+				value = strings.TrimSpace(value)
+				switch {
+				case indexPart == "unique":
+					unique = true
+				case indexPart == "concurrently":
+					concurrently = true
+				case indexPart == "desc":
+					part.Desc = true
+				case indexPart == "include":
+					include = true
+				case hasValue && key == "priority":
 					// Extract priority value
-					priorityStr := strings.TrimPrefix(part, "priority:")
 					var err error
-					priority, err = strconv.Atoi(priorityStr)
+					priority, err = strconv.Atoi(value)
 					if err != nil {
-						return fmt.Errorf("invalid priority value in index tag: %s", part)
+						return fmt.Errorf("invalid priority value in index tag: %s", indexPart)
 					}
-				} else {
+				case hasValue && key == "nulls":
+					part.Nulls = strings.ToUpper(value)
+					if part.Nulls != "FIRST" && part.Nulls != "LAST" {
+						return fmt.Errorf("invalid nulls value in index tag: %s", indexPart)
+					}
+				case hasValue && key == "expr":
+					part.Expression = value
+				case hasValue && key == "where":
+					where = value
+				case hasValue && key == "type":
+					var err error
+					if method, err = t.db.IndexMethod(value); err != nil {
+						return fmt.Errorf("invalid type value in index tag: %w", err)
+					}
+				case indexPart != "":
 					// This is the index name
-					idxName = part
+					idxName = indexPart
 				}
 			}
 			if idxName == "" {
-				idxName = col.Name() + "_index" // Default index name if not specified
+				idxName = t.defaultIndexName(unique, []IndexColumn{part}) // Default index name if not specified
+			} else {
+				idxName = t.identifierName(idxName)
 			}
-			indexNameAndColsAndPriority[idxName] = map[string]int{col.Name(): priority}
-		}
-		if uniqueTag, ok := tags[TAG_UNIQUE]; ok {
-			if uniqueTag == "true" || uniqueTag == "1" {
-				indexs = append(indexs, NewTableIndex(t, col.Name()+"_unique", []string{col.Name()}, true))
+			idx, ok := byName[idxName]
+			if !ok {
+				idx = &TableIndex{name: idxName, table: t}
+				byName[idxName] = idx
+				named = append(named, idx)
 			}
-		}
-	}
-	if len(indexNameAndColsAndPriority) != 0 {
-		for idxName, colMap := range indexNameAndColsAndPriority {
-			cols := make([]string, 0, len(colMap))
-			for colName := range colMap {
-				cols = append(cols, colName)
+			idx.isUnique = idx.isUnique || unique
+			idx.concurrently = idx.concurrently || concurrently
+			if where != "" {
+				idx.where = where
 			}
-			for i := 0; i < len(cols); i++ {
-				for j := i + 1; j < len(cols); j++ {
-					if colMap[cols[i]] < colMap[cols[j]] {
-						// Swap to maintain priority order
-						cols[i], cols[j] = cols[j], cols[i]
-					}
-				}
+			if method != "" {
+				idx.method = method
+			}
+			if include {
+				idx.include = append(idx.include, col.Name())
+			} else {
+				idx.columns = append(idx.columns, part)
+				priorities[idx] = append(priorities[idx], priority)
 			}
-			indexs = append(indexs, NewTableIndex(t, idxName, cols, false))
 		}
+		if col.IsUnique() {
+			idx := NewTableIndex(t, "", []string{col.Name()}, true)
+			idx.name = t.defaultIndexName(true, idx.columns)
+			indexs = append(indexs, idx)
+		}
+	}
+	for _, idx := range named {
+		if len(idx.columns) == 0 {
+			return fmt.Errorf("index %s of table %s has no key columns", idx.name, t.name)
+		}
+		// Order the columns by priority, columns with the same priority keep the field order
+		order := make([]int, len(idx.columns))
+		for i := range order {
+			order[i] = i
+		}
+		sort.SliceStable(order, func(i, j int) bool {
+			return priorities[idx][order[i]] < priorities[idx][order[j]]
+		})
+		columns := make([]IndexColumn, len(order))
+		for i, j := range order {
+			columns[i] = idx.columns[j]
+		}
+		idx.columns = columns
+		indexs = append(indexs, *idx)
 	}
 	t.indexes = indexs
 	return nil
 }
 
+// SearchVectorColumn is the generated tsvector column holding the text search document of the
+// searchable columns on PostgreSQL.
+const SearchVectorColumn = "search_vector"
+
+// constructSearch indexes the columns tagged with searchable for full-text search. PostgreSQL
+// gets a generated tsvector column with a GIN index, MariaDB a FULLTEXT index on the columns.
+// eg: db:"searchable" or db:"searchable:english" for the english text search configuration.
+func (t *Table) constructSearch() error {
+	var columns []IndexColumn
+	for _, col := range t.columns {
+		if !col.IsSearchable() {
+			continue
+		}
+		if fieldType := t.structType.FieldByIndex(t.fieldPaths[col.Name()]).Type; fieldType.Kind() != reflect.String &&
+			(fieldType.Kind() != reflect.Ptr || fieldType.Elem().Kind() != reflect.String) {
+			return fmt.Errorf("searchable column %s must be a string", col.Name())
+		}
+		config := col.GetStructTags()[TAG_SEARCHABLE]
+		if _, err := strconv.ParseBool(config); err == nil {
+			config = ""
+		}
+		if config != "" && t.searchConfig != "" && config != t.searchConfig {
+			return fmt.Errorf("searchable columns use different text search configurations %s and %s", t.searchConfig, config)
+		}
+		if config != "" {
+			t.searchConfig = config
+		}
+		columns = append(columns, Asc(col.Name()))
+	}
+	if len(columns) == 0 {
+		return nil
+	}
+	if t.searchConfig == "" {
+		t.searchConfig = defaultSearchConfig
+	}
+	quoted := make([]string, len(columns))
+	for i, col := range columns {
+		quoted[i] = t.db.QuoteIdentifier(col.Column)
+	}
+	expression := t.db.SearchVectorSql(quoted, t.searchConfig)
+	if expression == "" {
+		idx := TableIndex{columns: columns, method: "FULLTEXT", table: t}
+		idx.name = t.defaultIndexName(false, columns)
+		t.indexes = append(t.indexes, idx)
+		return nil
+	}
+	if t.Column(SearchVectorColumn) != nil {
+		return fmt.Errorf("column %s is reserved for the search vector", SearchVectorColumn)
+	}
+	col, err := t.db.GetColumnDefinitionByType(reflect.TypeOf(""), SearchVectorColumn,
+		map[string]string{TAG_NAME: SearchVectorColumn, TAG_TYPE: "tsvector", TAG_NULLABLE: "true"}, false)
+	if err != nil {
+		return err
+	}
+	col.SetGeneratedExpression(expression)
+	t.columns = append(t.columns, col)
+	vector := []IndexColumn{Asc(SearchVectorColumn)}
+	t.indexes = append(t.indexes, TableIndex{name: t.defaultIndexName(false, vector), columns: vector, method: "gin", table: t})
+	return nil
+}
+
+// constructConstraints constructs the foreign keys of the table from the references tags.
+// eg: db:"references:users(id);on_delete:CASCADE".
 func (t *Table) constructConstraints() error {
-	// This function is a placeholder for future implementation of foreign key constraints
-	// Currently, it does nothing but can be extended later
+	for _, col := range t.columns {
+		tags := col.GetStructTags()
+		references, ok := tags[TAG_REFERENCES]
+		if !ok {
+			continue
+		}
+		refTable, refColumns, err := parseReferences(references)
+		if err != nil {
+			return fmt.Errorf("column %s: %w", col.Name(), err)
+		}
+		if err := t.AddForeignKey("", []string{col.Name()}, refTable, refColumns); err != nil {
+			return err
+		}
+		t.constraints[len(t.constraints)-1].onDelete = tags[TAG_ON_DELETE]
+	}
+	return nil
+}
+
+// AddForeignKey adds a foreign key constraint from columns to the referenced columns of
+// referencedTable, an empty name defaults to fk_<table>_<columns>.
+func (t *Table) AddForeignKey(name string, columns []string, referencedTable string, referencedColumns []string) error {
+	if len(columns) == 0 || len(columns) != len(referencedColumns) {
+		return fmt.Errorf("foreign key of table %s needs the same number of columns and referenced columns", t.name)
+	}
+	for _, column := range columns {
+		if t.Column(column) == nil {
+			return fmt.Errorf("foreign key column %s not found in table %s", column, t.name)
+		}
+	}
+	if name == "" {
+		name = t.db.GetDB().NamingStrategy().ForeignKeyName(t.name, columns)
+	}
+	name = t.identifierName(name)
+	for _, fk := range t.constraints {
+		if fk.name == name {
+			return fmt.Errorf("duplicate foreign key %s in table %s", name, t.name)
+		}
+	}
+	t.constraints = append(t.constraints, *NewTableForeignKey(name, columns, referencedTable, referencedColumns))
 	return nil
 }
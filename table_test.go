@@ -0,0 +1,428 @@
+package aaronsql
+
+import (
+	"context"
+	"database/sql"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type testBaseModel struct {
+	ID        int64     `db:"name:id;primary"`
+	CreatedAt time.Time `db:"name:created_at"`
+	UpdatedAt time.Time `db:"name:updated_at"`
+}
+
+type testAddress struct {
+	Street string `db:"name:street"`
+	City   string `db:"name:city"`
+}
+
+type testCustomer struct {
+	testBaseModel
+	Name    string       `db:"name:name"`
+	Address testAddress  `db:"embed;prefix:addr_"`
+	Billing *testAddress `db:"embed;prefix:billing_"`
+}
+
+func registerTestDB(t *testing.T, dbName string, db DBInterface) {
+	globalDBInstances[dbName] = db
+	t.Cleanup(func() {
+		delete(globalDBInstances, dbName)
+	})
+}
+
+func TestNewTableFromStructEmbedded(t *testing.T) {
+	registerTestDB(t, "embedded_test", &PostgresDataBase{DataBase: DataBase{name: PostgresDB}})
+
+	table, err := NewTableFromStructWithDB(testCustomer{}, "customers", "embedded_test")
+	if err != nil {
+		t.Fatalf("Failed to create table from struct: %v", err)
+	}
+
+	expected := []string{"id", "created_at", "updated_at", "name", "addr_street", "addr_city", "billing_street", "billing_city"}
+	cols := table.Columns()
+	if len(cols) != len(expected) {
+		t.Fatalf("Expected %d columns, got %d", len(expected), len(cols))
+	}
+	for i, name := range expected {
+		if cols[i].Name() != name {
+			t.Errorf("Expected column %d to be %s, got %s", i, name, cols[i].Name())
+		}
+	}
+
+	customer := testCustomer{Name: "n", Address: testAddress{City: "Berlin"}}
+	customer.ID = 7
+	value, found := table.fieldByColumn(reflect.ValueOf(&customer).Elem(), table.Column("addr_city"))
+	if !found || value.Interface() != "Berlin" {
+		t.Errorf("Expected addr_city to resolve to Berlin, got %v (found=%t)", value, found)
+	}
+	value, found = table.fieldByColumn(reflect.ValueOf(&customer).Elem(), table.Column("id"))
+	if !found || value.Interface() != int64(7) {
+		t.Errorf("Expected id to resolve to 7, got %v (found=%t)", value, found)
+	}
+	if _, found = table.fieldByColumn(reflect.ValueOf(&customer).Elem(), table.Column("billing_city")); found {
+		t.Errorf("Expected billing_city behind a nil pointer to be skipped")
+	}
+}
+
+func TestNewTableFromStructEmbeddedValuer(t *testing.T) {
+	registerTestDB(t, "embedded_test", &PostgresDataBase{DataBase: DataBase{name: PostgresDB}})
+
+	// embedded Valuer and Scanner types are stored in one column, not flattened
+	type profile struct {
+		ID             int64 `db:"name:id;primary"`
+		sql.NullString `db:"name:nickname;type:VARCHAR(50);nullable"`
+	}
+	table, err := NewTableFromStructWithDB(profile{}, "profiles", "embedded_test")
+	if err != nil {
+		t.Fatalf("Failed to create table from struct: %v", err)
+	}
+	cols := table.Columns()
+	if len(cols) != 2 || cols[1].Name() != "nickname" || !cols[1].Nullable() {
+		t.Fatalf("Expected the embedded NullString to be the nullable column nickname, got %+v", cols)
+	}
+	record := profile{ID: 1, NullString: sql.NullString{String: "ace", Valid: true}}
+	value, found := table.fieldByColumn(reflect.ValueOf(&record).Elem(), cols[1])
+	if !found || value.Interface() != record.NullString {
+		t.Errorf("Expected nickname to resolve to the embedded field, got %v (found=%t)", value, found)
+	}
+}
+
+func TestNewTableFromStructDuplicateEmbeddedColumn(t *testing.T) {
+	registerTestDB(t, "embedded_test", &PostgresDataBase{DataBase: DataBase{name: PostgresDB}})
+
+	type duplicated struct {
+		testBaseModel
+		ID int64 `db:"name:id"`
+	}
+	if _, err := NewTableFromStructWithDB(duplicated{}, "duplicated", "embedded_test"); err == nil {
+		t.Fatalf("Expected duplicate column error")
+	}
+}
+
+func TestQuoteIdentifier(t *testing.T) {
+	postgres := &PostgresDataBase{DataBase: DataBase{name: PostgresDB}}
+	mariadb := &MariaDBDataBase{DataBase: DataBase{name: MariaDB}}
+	cases := []struct {
+		db       DBInterface
+		parts    []string
+		expected string
+	}{
+		{postgres, []string{"Order"}, `"Order"`},
+		{postgres, []string{"billing", "user"}, `"billing"."user"`},
+		{postgres, []string{"", "user"}, `"user"`},
+		{postgres, []string{`a"b`}, `"a""b"`},
+		{mariadb, []string{"order"}, "`order`"},
+		{mariadb, []string{"billing", "user"}, "`billing`.`user`"},
+		{mariadb, []string{"a`b"}, "`a``b`"},
+	}
+	for _, c := range cases {
+		if got := c.db.QuoteIdentifier(c.parts...); got != c.expected {
+			t.Errorf("Expected %s to quote %v as %s, got %s", c.db.Name(), c.parts, c.expected, got)
+		}
+	}
+}
+
+func TestCreateTableSQLQuotesIdentifiers(t *testing.T) {
+	type order struct {
+		ID    int64  `db:"name:id;primary"`
+		Order int    `db:"nullable:false"`
+		User  string `db:"name:user;width:20"`
+	}
+	registerTestDB(t, "quote_pg", &PostgresDataBase{DataBase: DataBase{name: PostgresDB}})
+	registerTestDB(t, "quote_maria", &MariaDBDataBase{DataBase: DataBase{name: MariaDB}})
+
+	for dbName, expected := range map[string]string{
+		"quote_pg":    `CREATE TABLE "group" ("id" BIGINT NOT NULL, "Order" INTEGER NOT NULL, "user" VARCHAR(20) NOT NULL, PRIMARY KEY ("id"));`,
+		"quote_maria": "CREATE TABLE `group` (`id` BIGINT NOT NULL, `Order` INT NOT NULL, `user` VARCHAR(20) NOT NULL, PRIMARY KEY (`id`)) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;",
+	} {
+		table, err := NewTableFromStructWithDB(order{}, "group", dbName)
+		if err != nil {
+			t.Fatalf("Failed to create table from struct: %v", err)
+		}
+		if got := table.db.GetCreateTableSQL(table.Schema(), table.Name(), table.Columns(), CreateTableOptions{}); got != expected {
+			t.Errorf("Expected create table SQL\n%s\ngot\n%s", expected, got)
+		}
+	}
+}
+
+func TestTableSchema(t *testing.T) {
+	type invoice struct {
+		ID int64 `db:"name:id;primary"`
+	}
+	postgres := &PostgresDataBase{DataBase: DataBase{name: PostgresDB}}
+	registerTestDB(t, "schema_pg", postgres)
+
+	table, err := NewTableFromStructWithDB(invoice{}, "invoices", "schema_pg", WithSchema("billing"))
+	if err != nil {
+		t.Fatalf("Failed to create table from struct: %v", err)
+	}
+	if table.Schema() != "billing" || table.qualifiedName(table.Schema()) != `"billing"."invoices"` {
+		t.Errorf("Expected table in schema billing, got %s", table.qualifiedName(table.Schema()))
+	}
+	if got := postgres.GetCreateTableSQL(table.Schema(), table.Name(), table.Columns(), CreateTableOptions{}); got != `CREATE TABLE "billing"."invoices" ("id" BIGINT NOT NULL, PRIMARY KEY ("id"));` {
+		t.Errorf("Unexpected create table SQL: %s", got)
+	}
+	if got := postgres.DropTableSql(table.Schema(), table.Name()); got != `DROP TABLE IF EXISTS "billing"."invoices";` {
+		t.Errorf("Unexpected drop table SQL: %s", got)
+	}
+	if got := postgres.CreateSchemaSql("billing"); got != `CREATE SCHEMA IF NOT EXISTS "billing";` {
+		t.Errorf("Unexpected create schema SQL: %s", got)
+	}
+	dropIndexSQL, err := renderSQL(postgres, DropIndexTemplate, SQLTemplateData{
+		Schema:    postgres.QuoteIdentifier(table.Schema()),
+		TableName: table.qualifiedName(table.Schema()),
+		IndexName: postgres.QuoteIdentifier("idx_total"),
+	})
+	if err != nil || dropIndexSQL != `DROP INDEX IF EXISTS "billing"."idx_total";` {
+		t.Errorf("Unexpected drop index SQL: %s (%v)", dropIndexSQL, err)
+	}
+
+	// Tables without a schema use the default schema of the database
+	postgres.SetSchema("tenant_a")
+	table, err = NewTableFromStructWithDB(invoice{}, "invoices", "schema_pg")
+	if err != nil {
+		t.Fatalf("Failed to create table from struct: %v", err)
+	}
+	if schema, _ := table.resolveSchema(context.Background()); schema != "tenant_a" {
+		t.Errorf("Expected the database schema to be used, got %s", schema)
+	}
+	postgres.SetSchema("")
+	if got := table.qualifiedName(table.Schema()); got != `"invoices"` {
+		t.Errorf("Expected an unqualified name without schema, got %s", got)
+	}
+
+	mariadb := &MariaDBDataBase{DataBase: DataBase{name: MariaDB}}
+	if got := mariadb.CreateSchemaSql("billing"); got != "CREATE DATABASE IF NOT EXISTS `billing`;" {
+		t.Errorf("Unexpected create schema SQL: %s", got)
+	}
+}
+
+func TestConcurrentIndexTag(t *testing.T) {
+	type event struct {
+		ID        int64     `db:"name:id;primary"`
+		CreatedAt time.Time `db:"name:created_at;index:idx_created_at,concurrently"`
+		Kind      string    `db:"name:kind;width:20;index:idx_kind"`
+	}
+	registerTestDB(t, "concurrent_test", &PostgresDataBase{DataBase: DataBase{name: PostgresDB}})
+
+	table, err := NewTableFromStructWithDB(event{}, "events", "concurrent_test")
+	if err != nil {
+		t.Fatalf("Failed to create table from struct: %v", err)
+	}
+	for _, idx := range table.Indexes() {
+		expected := idx.Name() == "idx_created_at"
+		if idx.IsConcurrently() != expected {
+			t.Errorf("Expected index %s concurrently=%t", idx.Name(), expected)
+		}
+		if !idx.IsValid() {
+			t.Errorf("Expected declared index %s to be valid", idx.Name())
+		}
+	}
+}
+
+func TestPostgresAutoIncrement(t *testing.T) {
+	type ticket struct {
+		ID     int64  `db:"name:id;primary;auto_increment;auto_increment_offset:1000"`
+		Number int32  `db:"name:number;serial;auto_increment_offset:500"`
+		Title  string `db:"name:title"`
+	}
+	registerTestDB(t, "auto_increment_test", &PostgresDataBase{DataBase: DataBase{name: PostgresDB}})
+
+	table, err := NewTableFromStructWithDB(ticket{}, "tickets", "auto_increment_test")
+	if err != nil {
+		t.Fatalf("Failed to create table from struct: %v", err)
+	}
+	if id := table.Column("id"); !id.IsAutoIncrement() || id.AutoIncrementOffset() != 1000 {
+		t.Errorf("Expected id to be auto-incrementing from 1000")
+	}
+	createSQL := table.db.GetCreateTableSQL("", table.name, table.columns, table.createTableOptions())
+	expected := `CREATE TABLE "tickets" ("id" BIGINT GENERATED BY DEFAULT AS IDENTITY (START WITH 1000) NOT NULL, ` +
+		`"number" SERIAL NOT NULL, "title" TEXT NOT NULL, PRIMARY KEY ("id")); ` +
+		`SELECT setval(pg_get_serial_sequence('"tickets"', 'number'), 500, false);`
+	if createSQL != expected {
+		t.Errorf("Expected %s, got %s", expected, createSQL)
+	}
+
+	got, err := renderSQL(table.db, AddAutoIncrementTemplate, SQLTemplateData{TableName: `"tickets"`, ColumnName: `"id"`, Start: 1000})
+	if err != nil {
+		t.Fatalf("Failed to render add auto increment template: %v", err)
+	}
+	if expected := `ALTER TABLE "tickets" ALTER COLUMN "id" ADD GENERATED BY DEFAULT AS IDENTITY (START WITH 1000);`; got != expected {
+		t.Errorf("Expected %s, got %s", expected, got)
+	}
+}
+
+func TestMariaDBAutoIncrement(t *testing.T) {
+	type ticket struct {
+		ID    int64  `db:"name:id;primary;auto_increment;auto_increment_offset:1000"`
+		Title string `db:"name:title;width:100"`
+	}
+	registerTestDB(t, "mariadb_auto_increment_test", &MariaDBDataBase{DataBase: DataBase{name: MariaDB}})
+
+	table, err := NewTableFromStructWithDB(ticket{}, "tickets", "mariadb_auto_increment_test")
+	if err != nil {
+		t.Fatalf("Failed to create table from struct: %v", err)
+	}
+	if id := table.Column("id"); !id.IsAutoIncrement() || id.AutoIncrementOffset() != 1000 {
+		t.Errorf("Expected id to be auto-incrementing from 1000")
+	}
+	if table.Column("title").IsAutoIncrement() {
+		t.Errorf("Expected title not to be auto-incrementing")
+	}
+	createSQL := table.db.GetCreateTableSQL("", table.name, table.columns, table.createTableOptions())
+	expected := "CREATE TABLE `tickets` (`id` BIGINT NOT NULL AUTO_INCREMENT, `title` VARCHAR(100) NOT NULL, PRIMARY KEY (`id`)) " +
+		"ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci AUTO_INCREMENT=1000;"
+	if createSQL != expected {
+		t.Errorf("Expected %s, got %s", expected, createSQL)
+	}
+}
+
+func TestInsertGeneratedKeys(t *testing.T) {
+	type ticket struct {
+		ID    int64  `db:"name:id;primary;auto_increment"`
+		Title string `db:"name:title"`
+	}
+	cases := []struct {
+		db       DBInterface
+		expected string
+	}{
+		{&PostgresDataBase{DataBase: DataBase{name: PostgresDB}}, `INSERT INTO "tickets" ("title") VALUES ($1) RETURNING "id";`},
+		{&MariaDBDataBase{DataBase: DataBase{name: MariaDB}}, "INSERT INTO `tickets` (`title`) VALUES (?);"},
+	}
+	for _, c := range cases {
+		conn := recordStatements(t, "insert_key_test", c.db)
+		conn.insertID = 42
+		table, err := NewTableFromStructWithDB(ticket{}, "tickets", "insert_key_test")
+		if err != nil {
+			t.Fatalf("Failed to create table from struct: %v", err)
+		}
+		record := ticket{Title: "Broken link"}
+		if err := table.Insert(&record); err != nil {
+			t.Fatalf("Failed to insert on %s: %v", c.db.Name(), err)
+		}
+		if record.ID != 42 {
+			t.Errorf("Expected the generated key to be written back on %s, got %d", c.db.Name(), record.ID)
+		}
+		if len(conn.statements) != 1 || conn.statements[0] != c.expected {
+			t.Errorf("Expected %s, got %v", c.expected, conn.statements)
+		}
+	}
+}
+
+func TestComments(t *testing.T) {
+	type invoice struct {
+		ID    int64  `db:"name:id;primary;comment:Invoice number"`
+		Total int64  `db:"name:total;comment:'Total; in cents, it''s net'"`
+		Notes string `db:"name:notes;nullable"`
+	}
+	registerTestDB(t, "comment_postgres_test", &PostgresDataBase{DataBase: DataBase{name: PostgresDB}})
+	registerTestDB(t, "comment_mariadb_test", &MariaDBDataBase{DataBase: DataBase{name: MariaDB}})
+
+	cases := []struct {
+		dbName   string
+		expected string
+	}{
+		{"comment_postgres_test", `CREATE TABLE "billing"."invoices" ("id" BIGINT NOT NULL, "total" BIGINT NOT NULL, "notes" TEXT, PRIMARY KEY ("id")); ` +
+			`COMMENT ON TABLE "billing"."invoices" IS 'Issued invoices'; ` +
+			`COMMENT ON COLUMN "billing"."invoices"."id" IS 'Invoice number'; ` +
+			`COMMENT ON COLUMN "billing"."invoices"."total" IS 'Total; in cents, it''s net';`},
+		{"comment_mariadb_test", "CREATE TABLE `billing`.`invoices` (`id` BIGINT NOT NULL COMMENT 'Invoice number', " +
+			"`total` BIGINT NOT NULL COMMENT 'Total; in cents, it''s net', `notes` TEXT, PRIMARY KEY (`id`)) " +
+			"ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='Issued invoices';"},
+	}
+	for _, c := range cases {
+		table, err := NewTableFromStructWithDB(invoice{}, "invoices", c.dbName, WithComment("Issued invoices"))
+		if err != nil {
+			t.Fatalf("Failed to create table from struct: %v", err)
+		}
+		if got := table.Column("total").Comment(); got != "Total; in cents, it's net" {
+			t.Errorf("Expected the unquoted comment, got %q", got)
+		}
+		if got := table.db.GetCreateTableSQL("billing", table.name, table.columns, table.createTableOptions()); got != c.expected {
+			t.Errorf("Expected %s, got %s", c.expected, got)
+		}
+	}
+
+	mariadb := globalDBInstances["comment_mariadb_test"]
+	got, err := renderSQL(mariadb, ColumnCommentTemplate, SQLTemplateData{
+		TableName:  "`invoices`",
+		ColumnName: "`total`",
+		Definition: "BIGINT NOT NULL",
+		Comment:    commentLiteral("Total"),
+	})
+	if err != nil {
+		t.Fatalf("Failed to render column comment template: %v", err)
+	}
+	if expected := "ALTER TABLE `invoices` MODIFY COLUMN `total` BIGINT NOT NULL COMMENT 'Total';"; got != expected {
+		t.Errorf("Expected %s, got %s", expected, got)
+	}
+}
+
+func TestCharsetCollation(t *testing.T) {
+	type session struct {
+		ID    int64  `db:"name:id;primary"`
+		Token string `db:"name:token;charset:utf8mb4;collate:utf8mb4_bin"`
+	}
+	type account struct {
+		ID   int64  `db:"name:id;primary"`
+		Name string `db:"name:name;collate:C"`
+	}
+	registerTestDB(t, "collate_postgres_test", &PostgresDataBase{DataBase: DataBase{name: PostgresDB}})
+	registerTestDB(t, "collate_mariadb_test", &MariaDBDataBase{DataBase: DataBase{name: MariaDB}})
+
+	cases := []struct {
+		model    interface{}
+		dbName   string
+		extra    map[string]string
+		expected string
+	}{
+		{account{}, "collate_postgres_test", nil,
+			`CREATE TABLE "auth"."sessions" ("id" BIGINT NOT NULL, "name" TEXT COLLATE "C" NOT NULL, PRIMARY KEY ("id"));`},
+		{session{}, "collate_mariadb_test", nil, "CREATE TABLE `auth`.`sessions` (`id` BIGINT NOT NULL, " +
+			"`token` TEXT CHARACTER SET utf8mb4 COLLATE utf8mb4_bin NOT NULL, PRIMARY KEY (`id`)) " +
+			"ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;"},
+		{session{}, "collate_mariadb_test", map[string]string{EXTRA_CHARSET: "latin1", EXTRA_COLLATE: "latin1_swedish_ci"},
+			"CREATE TABLE `auth`.`sessions` (`id` BIGINT NOT NULL, " +
+				"`token` TEXT CHARACTER SET utf8mb4 COLLATE utf8mb4_bin NOT NULL, PRIMARY KEY (`id`)) " +
+				"ENGINE=InnoDB DEFAULT CHARSET=latin1 COLLATE=latin1_swedish_ci;"},
+	}
+	for _, c := range cases {
+		table, err := NewTableFromStructWithDB(c.model, "sessions", c.dbName)
+		if err != nil {
+			t.Fatalf("Failed to create table from struct: %v", err)
+		}
+		table.SetExtra(c.extra)
+		if got := table.db.GetCreateTableSQL("auth", table.name, table.columns, table.createTableOptions()); got != c.expected {
+			t.Errorf("Expected %s, got %s", c.expected, got)
+		}
+	}
+
+	existing := &MariaDBColumn{BaseWidthColumn: newWidthColumnFromType(BaseColumn{
+		name: "token", charset: "utf8mb4", collation: "utf8mb4_unicode_ci",
+	}, "TEXT")}
+	table, err := NewTableFromStructWithDB(session{}, "sessions", "collate_mariadb_test")
+	if err != nil {
+		t.Fatalf("Failed to create table from struct: %v", err)
+	}
+	if sameCollation(existing, table.Column("token")) {
+		t.Errorf("Expected utf8mb4_unicode_ci to differ from utf8mb4_bin")
+	}
+	if !sameCollation(existing, table.Column("id")) {
+		t.Errorf("Expected columns without collation to keep the existing one")
+	}
+
+	got, err := renderSQL(globalDBInstances["collate_mariadb_test"], TableCharsetTemplate, SQLTemplateData{
+		TableName: "`sessions`",
+		Charset:   "utf8mb4",
+		Collation: "utf8mb4_bin",
+	})
+	if err != nil {
+		t.Fatalf("Failed to render table charset template: %v", err)
+	}
+	if expected := "ALTER TABLE `sessions` DEFAULT CHARACTER SET utf8mb4 COLLATE utf8mb4_bin;"; got != expected {
+		t.Errorf("Expected %s, got %s", expected, got)
+	}
+}
@@ -1,6 +1,13 @@
 package aaronsql
 
-import "strings"
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
 
 // Column tags
 const (
@@ -8,12 +15,18 @@ const (
 	TAG_IGNORE = "ignore"
 	// TAG_NAME indicates the name of the column in the database
 	TAG_NAME = "name"
-	// TAG_WIDTH indicates the width of the column
+	// TAG_WIDTH indicates the width of the column, eg: VARCHAR(width)
 	TAG_WIDTH = "width"
-	// TAG_CHARSET indicates the character set of the column
+	// TAG_CHARSET indicates the character set of the column, MariaDB only
 	TAG_CHARSET = "charset"
-	// TAG_PRECISION indicates the precision of the column
+	// TAG_COLLATE indicates the collation of the column, eg: collate:utf8mb4_bin
+	TAG_COLLATE = "collate"
+	// TAG_PRECISION indicates the precision of the column, formatted as "precision" or "precision,scale"
 	TAG_PRECISION = "precision"
+	// TAG_SCALE indicates the scale of decimal columns, it can also be given as "precision:p,s"
+	TAG_SCALE = "scale"
+	// TAG_FIXED indicates that a string column with a width is a fixed width CHAR column
+	TAG_FIXED = "fixed"
 	// TAG_DEFAULT indicates the default value of the column
 	TAG_DEFAULT = "default"
 	// TAG_UNIQUE indicates that the column should be unique
@@ -26,6 +39,11 @@ const (
 	TAG_NULLABLE = "nullable"
 	// TAG_AUTO_INCREMENT indicates that the column is auto-incrementing
 	TAG_AUTO_INCREMENT = "auto_increment"
+	// TAG_AUTO_INCREMENT_OFFSET indicates the first value of an auto-incrementing column
+	TAG_AUTO_INCREMENT_OFFSET = "auto_increment_offset"
+	// TAG_SERIAL indicates that an auto-incrementing column is a SERIAL column on PostgreSQL
+	// instead of an identity column
+	TAG_SERIAL = "serial"
 	// TAG_AUTO_VERSION indicates that the column is an auto versioning column
 	TAG_AUTO_VERSION = "auto_version"
 	// TAG_UPDATED_AT indicates that the column is an updated_at timestamp column
@@ -36,6 +54,28 @@ const (
 	TAG_ALLOW_ZERO = "allow_zero"
 	// TAG_EXTRA indicates extra information about the column
 	TAG_EXTRA = "extra"
+	// TAG_TYPE indicates the sql type of the column, it overrides the type mapped from the field type
+	TAG_TYPE = "type"
+	// TAG_JSON indicates that the column stores the field as a JSON document
+	TAG_JSON = "json"
+	// TAG_UUID indicates that the column is stored as an UUID
+	TAG_UUID = "uuid"
+	// TAG_EMBED indicates that the fields of a named struct field are flattened into columns
+	TAG_EMBED = "embed"
+	// TAG_PREFIX indicates the prefix prepended to the column names of an embedded struct
+	TAG_PREFIX = "prefix"
+	// TAG_REFERENCES indicates a foreign key to the referenced table and columns, eg: references:users(id)
+	TAG_REFERENCES = "references"
+	// TAG_ON_DELETE indicates the ON DELETE action of the foreign key, eg: on_delete:CASCADE
+	TAG_ON_DELETE = "on_delete"
+	// TAG_SEARCHABLE indicates that the column is full-text searchable, the value is the text
+	// search configuration on PostgreSQL, eg: searchable:english
+	TAG_SEARCHABLE = "searchable"
+	// TAG_CHECK indicates a CHECK constraint on the column, eg: check:price >= 0
+	TAG_CHECK = "check"
+	// TAG_COMMENT indicates the comment of the column, it may be quoted as a SQL literal, eg:
+	// comment:'Total, in cents'
+	TAG_COMMENT = "comment"
 	// TAG_DEFAULT_PART_QUOTE is used to quote the part in model tag
 	TAG_DEFAULT_PART_QUOTE = ";"
 	// TAG_DEFAULT_KEY_VALUE_QUOTE is used to separate key and value in model tag
@@ -51,22 +91,287 @@ func SetDefaultModelDBTagKey(key string) {
 	defaultModelDBTagKey = key
 }
 
+// tagValueType is the type of the value a tag key accepts.
+type tagValueType int
+
+const (
+	// tagValueFlag is a boolean, a key without value means true
+	tagValueFlag tagValueType = iota
+	tagValueInt
+	tagValueString
+	// tagValuePrecision is formatted as "precision" or "precision,scale"
+	tagValuePrecision
+)
+
+// tagSpec declares a known tag key.
+type tagSpec struct {
+	key       string
+	aliases   []string
+	valueType tagValueType
+	// conflicts lists the keys which cannot be enabled together with this key
+	conflicts []string
+	// kinds restricts the key to fields of these kinds, described by kindsName in errors
+	kinds     []reflect.Kind
+	kindsName string
+}
+
+var (
+	integerKinds = []reflect.Kind{reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64}
+	stringKinds = []reflect.Kind{reflect.String}
+)
+
+// tagSchema declares all tag keys understood by the library, strict mode rejects any other key.
+var tagSchema = []tagSpec{
+	{key: TAG_IGNORE, valueType: tagValueFlag},
+	{key: TAG_NAME, valueType: tagValueString},
+	{key: TAG_TYPE, valueType: tagValueString},
+	{key: TAG_WIDTH, aliases: []string{"length"}, valueType: tagValueInt},
+	{key: TAG_FIXED, valueType: tagValueFlag, kinds: stringKinds, kindsName: "a string"},
+	{key: TAG_PRECISION, valueType: tagValuePrecision},
+	{key: TAG_SCALE, valueType: tagValueInt},
+	{key: TAG_CHARSET, valueType: tagValueString},
+	{key: TAG_COLLATE, valueType: tagValueString},
+	{key: TAG_DEFAULT, valueType: tagValueString},
+	{key: TAG_UNIQUE, valueType: tagValueFlag},
+	{key: TAG_INDEX, valueType: tagValueString},
+	{key: TAG_PRIMARY, aliases: []string{"primary_key"}, valueType: tagValueFlag, conflicts: []string{TAG_NULLABLE}},
+	{key: TAG_NULLABLE, valueType: tagValueFlag},
+	{key: TAG_AUTO_INCREMENT, aliases: []string{"autoincrement"}, valueType: tagValueFlag, conflicts: []string{TAG_DEFAULT, TAG_NULLABLE}, kinds: integerKinds, kindsName: "an integer"},
+	{key: TAG_AUTO_INCREMENT_OFFSET, valueType: tagValueInt, kinds: integerKinds, kindsName: "an integer"},
+	{key: TAG_SERIAL, valueType: tagValueFlag, conflicts: []string{TAG_DEFAULT, TAG_NULLABLE}, kinds: integerKinds, kindsName: "an integer"},
+	{key: TAG_AUTO_VERSION, valueType: tagValueFlag, kinds: integerKinds, kindsName: "an integer"},
+	{key: TAG_UPDATED_AT, valueType: tagValueFlag, conflicts: []string{TAG_CREATED_AT}},
+	{key: TAG_CREATED_AT, valueType: tagValueFlag},
+	{key: TAG_ALLOW_ZERO, valueType: tagValueFlag},
+	{key: TAG_EXTRA, valueType: tagValueString},
+	{key: TAG_JSON, valueType: tagValueFlag, conflicts: []string{TAG_UUID}},
+	{key: TAG_UUID, valueType: tagValueFlag},
+	{key: TAG_EMBED, valueType: tagValueFlag},
+	{key: TAG_PREFIX, valueType: tagValueString},
+	{key: TAG_REFERENCES, valueType: tagValueString},
+	{key: TAG_ON_DELETE, valueType: tagValueString},
+	{key: TAG_SEARCHABLE, valueType: tagValueString, kinds: stringKinds, kindsName: "a string"},
+	{key: TAG_CHECK, valueType: tagValueString},
+	{key: TAG_COMMENT, valueType: tagValueString},
+}
+
+var (
+	tagSpecs   = make(map[string]*tagSpec)
+	tagAliases = make(map[string]string)
+)
+
+func init() {
+	for i := range tagSchema {
+		spec := &tagSchema[i]
+		tagSpecs[spec.key] = spec
+		for _, alias := range spec.aliases {
+			tagAliases[alias] = spec.key
+		}
+	}
+}
+
+// TagError reports an invalid tag of a struct field.
+type TagError struct {
+	Field   string
+	Key     string
+	Message string
+}
+
+func (e *TagError) Error() string {
+	return fmt.Sprintf("field %s: tag %q %s", e.Field, e.Key, e.Message)
+}
+
+// tagFlag returns whether the flag key is enabled, a key without value is enabled.
+func tagFlag(tags map[string]string, key string) bool {
+	v, ok := tags[key]
+	if !ok {
+		return false
+	}
+	if v == "" {
+		return true
+	}
+	b, _ := strconv.ParseBool(v)
+	return b
+}
+
+// validateTags checks the tags of field against tagSchema: unknown keys, malformed values,
+// keys not applicable to the field type and conflicting keys are reported.
+func validateTags(field reflect.StructField, tags map[string]string) error {
+	fieldType := field.Type
+	if fieldType.Kind() == reflect.Ptr {
+		fieldType = fieldType.Elem()
+	}
+	var errs []error
+	report := func(key string, format string, args ...interface{}) {
+		errs = append(errs, &TagError{Field: field.Name, Key: key, Message: fmt.Sprintf(format, args...)})
+	}
+	keys := make([]string, 0, len(tags))
+	for key := range tags {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		value := tags[key]
+		spec, ok := tagSpecs[key]
+		if !ok {
+			report(key, "is unknown")
+			continue
+		}
+		switch spec.valueType {
+		case tagValueFlag:
+			if _, err := strconv.ParseBool(value); value != "" && err != nil {
+				report(key, "expects a boolean, got %q", value)
+			}
+		case tagValueInt:
+			if n, err := strconv.Atoi(value); err != nil || n < 0 {
+				report(key, "expects a non-negative integer, got %q", value)
+			}
+		case tagValuePrecision:
+			if _, _, ok := parsePrecision(tags); !ok {
+				report(key, "expects \"precision\" or \"precision,scale\", got %q", value)
+			}
+		}
+		if len(spec.kinds) > 0 && tagFlagOrValue(spec, tags) {
+			allowed := false
+			for _, kind := range spec.kinds {
+				allowed = allowed || fieldType.Kind() == kind
+			}
+			if !allowed {
+				report(key, "requires %s field, got %s", spec.kindsName, fieldType.String())
+			}
+		}
+		for _, conflict := range spec.conflicts {
+			if _, ok := tags[conflict]; ok && tagFlagOrValue(spec, tags) && tagFlagOrValue(tagSpecs[conflict], tags) {
+				report(key, "conflicts with %q", conflict)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// tagFlagOrValue returns whether the key of spec is set, flags must be enabled as well.
+func tagFlagOrValue(spec *tagSpec, tags map[string]string) bool {
+	if spec.valueType == tagValueFlag {
+		return tagFlag(tags, spec.key)
+	}
+	_, ok := tags[spec.key]
+	return ok
+}
+
+// tagEntry is a key and its value as written in a model tag.
+type tagEntry struct {
+	key   string
+	value string
+}
+
+// parseTagString parses a model tag into a map, aliases are replaced by their canonical keys.
+// When a key is repeated the last value wins, malformed tags are parsed as far as possible.
 func parseTagString(tagStr string) map[string]string {
 	if tagStr == "" {
 		return nil
 	}
-	tagMap := make(map[string]string)
-	parts := strings.Split(tagStr, TAG_DEFAULT_PART_QUOTE)
-	for _, part := range parts {
-		part = strings.TrimSpace(part)
-		if part == "" {
+	entries, _ := parseTagEntries(tagStr)
+	tagMap := make(map[string]string, len(entries))
+	for _, entry := range entries {
+		tagMap[entry.key] = entry.value
+	}
+	return tagMap
+}
+
+// parseTagEntries tokenizes a model tag into its entries in order, aliases are replaced by
+// their canonical keys. The grammar is:
+//
+//	tag   = entry { ";" entry }
+//	entry = key [ ":" value ]
+//
+// Text between single quotes is taken verbatim including the quotes, so SQL string literals
+// need no escaping. Outside of quotes a backslash escapes the next character, eg: \; \: \'
+// or \\. The key ends at the first unquoted, unescaped colon, later colons belong to the value:
+//
+//	default:'a;b'            -> default = 'a;b'
+//	default:now()::timestamp -> default = now()::timestamp
+//	default:'it''s'          -> default = 'it''s'
+//	extra:a\;b               -> extra = a;b
+//
+// Unescaped whitespace around keys and values is trimmed. On a syntax error the entries parsed
+// so far are returned with it.
+func parseTagEntries(tagStr string) ([]tagEntry, error) {
+	var (
+		entries []tagEntry
+		buf     []byte
+		key     string
+		hasKey  bool
+		inQuote bool
+		// keep is the length of buf up to the last character which is not trimmed
+		keep       int
+		quoteStart int
+	)
+	flush := func() {
+		value := string(buf[:keep])
+		if !hasKey {
+			key, value = value, ""
+		}
+		if key != "" {
+			if canonical, ok := tagAliases[key]; ok {
+				key = canonical
+			}
+			entries = append(entries, tagEntry{key: key, value: value})
+		}
+		buf, key, hasKey, keep = buf[:0], "", false, 0
+	}
+	for i := 0; i < len(tagStr); i++ {
+		c := tagStr[i]
+		if inQuote {
+			buf = append(buf, c)
+			keep = len(buf)
+			inQuote = c != '\''
 			continue
 		}
-		if kv := strings.SplitN(part, TAG_DEFAULT_KEY_VALUE_QUOTE, 2); len(kv) == 2 {
-			tagMap[kv[0]] = kv[1]
-		} else if len(kv) == 1 {
-			tagMap[kv[0]] = ""
+		switch c {
+		case '\\':
+			if i+1 == len(tagStr) {
+				flush()
+				return entries, fmt.Errorf("tag %q ends with a dangling escape", tagStr)
+			}
+			i++
+			buf = append(buf, tagStr[i])
+			keep = len(buf)
+		case '\'':
+			inQuote, quoteStart = true, i
+			buf = append(buf, c)
+			keep = len(buf)
+		case TAG_DEFAULT_PART_QUOTE[0]:
+			flush()
+		case TAG_DEFAULT_KEY_VALUE_QUOTE[0]:
+			if hasKey {
+				buf = append(buf, c)
+				keep = len(buf)
+				continue
+			}
+			key, hasKey = string(buf[:keep]), true
+			buf, keep = buf[:0], 0
+		case ' ', '\t', '\n', '\r':
+			if keep > 0 {
+				buf = append(buf, c)
+			}
+		default:
+			buf = append(buf, c)
+			keep = len(buf)
 		}
 	}
-	return tagMap
+	flush()
+	if inQuote {
+		return entries, fmt.Errorf("tag %q has an unterminated quote at offset %d", tagStr, quoteStart)
+	}
+	return entries, nil
+}
+
+// unquoteTagValue returns the text of a value written as a single quoted SQL literal, doubled
+// quotes inside the literal are unescaped. Other values are returned unchanged.
+func unquoteTagValue(value string) string {
+	if len(value) < 2 || value[0] != '\'' || value[len(value)-1] != '\'' {
+		return value
+	}
+	return strings.ReplaceAll(value[1:len(value)-1], "''", "'")
 }
@@ -0,0 +1,197 @@
+package aaronsql
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseTagStringAliases(t *testing.T) {
+	tags := parseTagString("primary_key:true;length:100;autoincrement")
+	for key, value := range map[string]string{TAG_PRIMARY: "true", TAG_WIDTH: "100", TAG_AUTO_INCREMENT: ""} {
+		if got, ok := tags[key]; !ok || got != value {
+			t.Errorf("Expected tag %s to be %q, got %q (present=%t)", key, value, got, ok)
+		}
+	}
+	if !tagFlag(tags, TAG_PRIMARY) || !tagFlag(tags, TAG_AUTO_INCREMENT) || tagFlag(tags, TAG_NULLABLE) {
+		t.Errorf("Unexpected flags for %v", tags)
+	}
+}
+
+func TestStrictTags(t *testing.T) {
+	registerTestDB(t, "strict_test", &MariaDBDataBase{DataBase: DataBase{name: MariaDB}})
+
+	type valid struct {
+		ID   int64  `db:"name:id;primary_key:true;auto_increment:true"`
+		Name string `db:"name:name;width:100;nullable:false;index:idx_name"`
+	}
+	if _, err := NewTableFromStructWithDB(valid{}, "valid", "strict_test", WithStrictTags()); err != nil {
+		t.Fatalf("Expected valid tags to pass strict mode: %v", err)
+	}
+
+	type invalid struct {
+		ID     int64  `db:"name:id;primary;nullable:true"`
+		Name   string `db:"name:name;auto_increment"`
+		Email  string `db:"name:email;lenght:100"`
+		Active bool   `db:"name:active;unique:yes"`
+	}
+	_, err := NewTableFromStructWithDB(invalid{}, "invalid", "strict_test", WithStrictTags())
+	if err == nil {
+		t.Fatalf("Expected strict mode to reject invalid tags")
+	}
+	for _, expected := range []string{
+		`field ID: tag "primary" conflicts with "nullable"`,
+		`field Name: tag "auto_increment" requires an integer field`,
+		`field Email: tag "lenght" is unknown`,
+		`field Active: tag "unique" expects a boolean`,
+	} {
+		if !strings.Contains(err.Error(), expected) {
+			t.Errorf("Expected error to contain %q, got:\n%v", expected, err)
+		}
+	}
+	var tagErr *TagError
+	if !errors.As(err, &tagErr) {
+		t.Errorf("Expected a TagError, got %T", err)
+	}
+
+	if _, err := NewTableFromStructWithDB(invalid{}, "invalid", "strict_test"); err != nil {
+		t.Errorf("Expected invalid tags to be ignored without strict mode: %v", err)
+	}
+
+	type untyped struct {
+		ID    int64     `db:"name:id;primary;nullable:true"`
+		Total testMoney `db:"name:total"`
+	}
+	_, err = NewTableFromStructWithDB(untyped{}, "untyped", "strict_test", WithStrictTags())
+	for _, expected := range []string{
+		`field ID: tag "primary" conflicts with "nullable"`,
+		`failed to create column for field Total`,
+	} {
+		if err == nil || !strings.Contains(err.Error(), expected) {
+			t.Errorf("Expected error to contain %q, got:\n%v", expected, err)
+		}
+	}
+}
+
+func TestParseTagEntries(t *testing.T) {
+	cases := []struct {
+		tag      string
+		expected []tagEntry
+	}{
+		{`name:id;primary`, []tagEntry{{TAG_NAME, "id"}, {TAG_PRIMARY, ""}}},
+		{`default:'a;b'`, []tagEntry{{TAG_DEFAULT, "'a;b'"}}},
+		{`default:now()::timestamp`, []tagEntry{{TAG_DEFAULT, "now()::timestamp"}}},
+		{`default:'it''s; fine';name:x`, []tagEntry{{TAG_DEFAULT, "'it''s; fine'"}, {TAG_NAME, "x"}}},
+		{`default:'a\b'`, []tagEntry{{TAG_DEFAULT, `'a\b'`}}},
+		{`extra:a\;b\\c;name: padded `, []tagEntry{{TAG_EXTRA, `a;b\c`}, {TAG_NAME, "padded"}}},
+		{`extra:\ x\ `, []tagEntry{{TAG_EXTRA, " x "}}},
+		{`index:idx_a;index:idx_b;length:10`, []tagEntry{{TAG_INDEX, "idx_a"}, {TAG_INDEX, "idx_b"}, {TAG_WIDTH, "10"}}},
+		{` ; ;nullable`, []tagEntry{{TAG_NULLABLE, ""}}},
+	}
+	for _, c := range cases {
+		entries, err := parseTagEntries(c.tag)
+		if err != nil {
+			t.Errorf("Unexpected error for %q: %v", c.tag, err)
+			continue
+		}
+		if !reflect.DeepEqual(entries, c.expected) {
+			t.Errorf("Expected %q to parse to %v, got %v", c.tag, c.expected, entries)
+		}
+	}
+
+	for _, tag := range []string{`default:'a;b`, `extra:a\`} {
+		if _, err := parseTagEntries(tag); err == nil {
+			t.Errorf("Expected a syntax error for %q", tag)
+		}
+	}
+}
+
+// formatTagEntries encodes entries as a model tag which parseTagEntries must parse back into
+// the same entries. Balanced quoted text is written verbatim, other special characters are escaped.
+func formatTagEntries(entries []tagEntry) string {
+	parts := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		part := escapeTagText(entry.key, false)
+		if entry.value != "" {
+			part += TAG_DEFAULT_KEY_VALUE_QUOTE + escapeTagText(entry.value, true)
+		}
+		parts = append(parts, part)
+	}
+	return strings.Join(parts, TAG_DEFAULT_PART_QUOTE)
+}
+
+// escapeTagText escapes the characters of text which have a meaning in a model tag. Balanced
+// quoted text in values is kept verbatim, keys escape quotes and colons as well.
+func escapeTagText(text string, isValue bool) string {
+	allowQuotes := isValue && strings.Count(text, "'")%2 == 0
+	var sb strings.Builder
+	inQuote := false
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		switch {
+		case inQuote:
+			inQuote = c != '\''
+		case c == '\'' && allowQuotes:
+			inQuote = true
+		case c == '\\' || c == '\'' || c == TAG_DEFAULT_PART_QUOTE[0]:
+			sb.WriteByte('\\')
+		case c == TAG_DEFAULT_KEY_VALUE_QUOTE[0] && !isValue:
+			sb.WriteByte('\\')
+		case (i == 0 || i == len(text)-1) && strings.IndexByte(" \t\n\r", c) >= 0:
+			sb.WriteByte('\\')
+		}
+		sb.WriteByte(c)
+	}
+	return sb.String()
+}
+
+func TestFormatTagEntriesRoundTrip(t *testing.T) {
+	values := []string{
+		"'a;b'",
+		"now()::timestamp",
+		"'it''s'",
+		"it's",
+		`a;b\c`,
+		"state IN ('a', 'b;c')",
+		" leading and trailing ",
+		"'unbalanced",
+		"x:y",
+		"",
+	}
+	for _, value := range values {
+		entries := []tagEntry{{TAG_NAME, "col"}, {TAG_DEFAULT, value}, {TAG_NULLABLE, ""}}
+		tag := formatTagEntries(entries)
+		parsed, err := parseTagEntries(tag)
+		if err != nil {
+			t.Errorf("Failed to parse formatted tag %q: %v", tag, err)
+			continue
+		}
+		if !reflect.DeepEqual(parsed, entries) {
+			t.Errorf("Expected %q to round trip through %q, got %v", value, tag, parsed)
+		}
+	}
+	if tag := formatTagEntries([]tagEntry{{TAG_DEFAULT, "'a;b'"}}); tag != "default:'a;b'" {
+		t.Errorf("Expected quoted text to be written verbatim, got %q", tag)
+	}
+}
+
+func TestUnquoteTagValue(t *testing.T) {
+	for value, expected := range map[string]string{"'it''s'": "it's", "plain": "plain", "'": "'", "''": ""} {
+		if got := unquoteTagValue(value); got != expected {
+			t.Errorf("Expected %q to unquote to %q, got %q", value, expected, got)
+		}
+	}
+}
+
+func TestStrictTagsSyntaxError(t *testing.T) {
+	registerTestDB(t, "strict_test", &MariaDBDataBase{DataBase: DataBase{name: MariaDB}})
+
+	type unterminated struct {
+		Name string `db:"name:name;default:'abc"`
+	}
+	_, err := NewTableFromStructWithDB(unterminated{}, "unterminated", "strict_test", WithStrictTags())
+	if err == nil || !strings.Contains(err.Error(), "unterminated quote") {
+		t.Fatalf("Expected an unterminated quote error, got %v", err)
+	}
+}
@@ -0,0 +1,211 @@
+package aaronsql
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"text/template"
+)
+
+// SQLTemplateName identifies one of the SQL templates of a dialect.
+type SQLTemplateName string
+
+const (
+	InsertTemplate        SQLTemplateName = "insert"
+	UpdateTemplate        SQLTemplateName = "update"
+	SelectTemplate        SQLTemplateName = "select"
+	QueryTemplate         SQLTemplateName = "query"
+	SearchTemplate        SQLTemplateName = "search"
+	SearchRankTemplate    SQLTemplateName = "search_rank"
+	CreateIndexTemplate   SQLTemplateName = "create_index"
+	DropIndexTemplate     SQLTemplateName = "drop_index"
+	CreateColumnTemplate  SQLTemplateName = "create_column"
+	UpdateColumnTemplate  SQLTemplateName = "update_column"
+	DropColumnTemplate    SQLTemplateName = "drop_column"
+	RenameColumnTemplate  SQLTemplateName = "rename_column"
+	AddForeignKeyTemplate SQLTemplateName = "add_foreign_key"
+	AddCheckTemplate      SQLTemplateName = "add_check"
+	DropCheckTemplate     SQLTemplateName = "drop_check"
+	CreateEnumTemplate    SQLTemplateName = "create_enum"
+	AddEnumValueTemplate  SQLTemplateName = "add_enum_value"
+	// AddAutoIncrementTemplate makes an existing column auto-incrementing
+	AddAutoIncrementTemplate SQLTemplateName = "add_auto_increment"
+	// TableCommentTemplate and ColumnCommentTemplate set the comment of a table or column, an
+	// empty Comment clears it
+	TableCommentTemplate  SQLTemplateName = "table_comment"
+	ColumnCommentTemplate SQLTemplateName = "column_comment"
+	// TableCharsetTemplate changes the default character set and collation of a table
+	TableCharsetTemplate SQLTemplateName = "table_charset"
+)
+
+// SQLTemplateData is the data the SQL templates are rendered with. Names are already quoted
+// with the dialect's QuoteIdentifier, lists are joined with ", " and conditions with " AND ".
+type SQLTemplateData struct {
+	// Schema is the quoted schema of the table, empty for the default schema
+	Schema string
+	// TableName is the quoted table name, qualified with the schema
+	TableName       string
+	IndexName       string
+	ColumnName      string
+	ColumnType      string
+	Columns         string
+	Values          string
+	Updates         string
+	Conditions      string
+	ConflictColumns string
+	// NewColumnName is the quoted name a column is renamed to
+	NewColumnName string
+	// Definition is the whole column definition following its name, comments excluded
+	Definition string
+	// Returning is the quoted column an insert reads the generated key back from, empty for none
+	Returning string
+
+	// Include lists the quoted non-key columns of an index, Where is the predicate of a partial
+	// index, both are empty when unused
+	Include string
+	Where   string
+	// Method is the index method in the dialect's spelling, eg: gin or FULLTEXT, empty for the
+	// default B-tree
+	Method string
+
+	// ConstraintName, ReferencedTable and ReferencedColumns describe a foreign key, OnDelete is
+	// its referential action, empty for the default one
+	ConstraintName    string
+	ReferencedTable   string
+	ReferencedColumns string
+	OnDelete          string
+	// Expression is the condition of a CHECK constraint
+	Expression string
+	// TypeName is the qualified name of an enum type, Values lists its quoted values
+	TypeName string
+	// Start is the first value of an auto-increment column, zero for the default
+	Start int64
+	// Comment is the quoted comment of a table or column, empty for none
+	Comment string
+	// Charset and Collation are the default character set and collation of a table
+	Charset   string
+	Collation string
+
+	// OrderBy, Limit and Offset shape the records of a query, zero Limit and Offset are unset
+	OrderBy string
+	Limit   int
+	Offset  int
+	// SearchConfig is the quoted text search configuration of a full-text search
+	SearchConfig string
+
+	// Unique is set for unique indexes
+	Unique bool
+	// IfNotExists is set when the statement must not fail if the object already exists
+	IfNotExists bool
+	// Concurrently is set when an index is built or dropped without blocking writes
+	Concurrently bool
+
+	// TypeChanged, NullableChanged and DefaultChanged tell which parts of a column are updated,
+	// Nullable and Default are their new values, an empty Default drops the default
+	TypeChanged     bool
+	NullableChanged bool
+	DefaultChanged  bool
+	Nullable        bool
+	Default         string
+}
+
+// parsedTemplates caches parsed templates by their text.
+var parsedTemplates sync.Map
+
+func parseSQLTemplate(text string) (*template.Template, error) {
+	if tpl, ok := parsedTemplates.Load(text); ok {
+		return tpl.(*template.Template), nil
+	}
+	tpl, err := template.New("sql").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, err
+	}
+	parsedTemplates.Store(text, tpl)
+	return tpl, nil
+}
+
+// SetTemplate overrides a SQL template of the dialect for this database instance, an empty text
+// restores the dialect's template. It should be called before the database is used.
+func (d *DataBase) SetTemplate(name SQLTemplateName, text string) error {
+	if text == "" {
+		delete(d.templates, name)
+		return nil
+	}
+	if _, err := parseSQLTemplate(text); err != nil {
+		return fmt.Errorf("invalid %s template: %w", name, err)
+	}
+	if d.templates == nil {
+		d.templates = make(map[SQLTemplateName]string)
+	}
+	d.templates[name] = text
+	return nil
+}
+
+// sqlTemplate returns the template text of db, overrides take precedence over the dialect.
+func sqlTemplate(db DBInterface, name SQLTemplateName) string {
+	if text, ok := db.GetDB().templates[name]; ok {
+		return text
+	}
+	switch name {
+	case InsertTemplate:
+		return db.InsertSqlTemplate()
+	case UpdateTemplate:
+		return db.UpdateSqlTemplate()
+	case SelectTemplate:
+		return db.SelectSqlTemplate()
+	case QueryTemplate:
+		return db.QuerySqlTemplate()
+	case SearchTemplate:
+		return db.SearchSqlTemplate()
+	case SearchRankTemplate:
+		return db.SearchRankSqlTemplate()
+	case CreateIndexTemplate:
+		return db.CreateIndexSqlTemplate()
+	case DropIndexTemplate:
+		return db.DropIndexSqlTemplate()
+	case CreateColumnTemplate:
+		return db.CreateColumnSqlTemplate()
+	case UpdateColumnTemplate:
+		return db.UpdateColumnSqlTemplate()
+	case DropColumnTemplate:
+		return db.DropColumnSqlTemplate()
+	case RenameColumnTemplate:
+		return db.RenameColumnSqlTemplate()
+	case AddForeignKeyTemplate:
+		return db.AddForeignKeySqlTemplate()
+	case AddCheckTemplate:
+		return db.AddCheckSqlTemplate()
+	case DropCheckTemplate:
+		return db.DropCheckSqlTemplate()
+	case CreateEnumTemplate:
+		return db.CreateEnumSqlTemplate()
+	case AddEnumValueTemplate:
+		return db.AddEnumValueSqlTemplate()
+	case AddAutoIncrementTemplate:
+		return db.AddAutoIncrementSqlTemplate()
+	case TableCommentTemplate:
+		return db.TableCommentSqlTemplate()
+	case ColumnCommentTemplate:
+		return db.ColumnCommentSqlTemplate()
+	case TableCharsetTemplate:
+		return db.TableCharsetSqlTemplate()
+	}
+	return ""
+}
+
+// renderSQL renders the template name of db with data.
+func renderSQL(db DBInterface, name SQLTemplateName, data SQLTemplateData) (string, error) {
+	text := sqlTemplate(db, name)
+	if text == "" {
+		return "", fmt.Errorf("%s template is not supported for database: %s", name, db.Name())
+	}
+	tpl, err := parseSQLTemplate(text)
+	if err != nil {
+		return "", fmt.Errorf("invalid %s template: %w", name, err)
+	}
+	var sb strings.Builder
+	if err := tpl.Execute(&sb, data); err != nil {
+		return "", fmt.Errorf("failed to render %s template: %w", name, err)
+	}
+	return sb.String(), nil
+}
@@ -0,0 +1,157 @@
+package aaronsql
+
+import (
+	"testing"
+)
+
+func TestRenderSQLCreateIndex(t *testing.T) {
+	postgres := &PostgresDataBase{DataBase: DataBase{name: PostgresDB}}
+	mariadb := &MariaDBDataBase{DataBase: DataBase{name: MariaDB}}
+	cases := []struct {
+		db       DBInterface
+		data     SQLTemplateData
+		expected string
+	}{
+		{postgres, SQLTemplateData{TableName: `"users"`, IndexName: `"idx_email"`, Columns: `"email"`, Unique: true, IfNotExists: true},
+			`CREATE UNIQUE INDEX IF NOT EXISTS "idx_email" ON "users" ("email");`},
+		{postgres, SQLTemplateData{TableName: `"users"`, IndexName: `"idx_name"`, Columns: `"name"`},
+			`CREATE INDEX "idx_name" ON "users" ("name");`},
+		{mariadb, SQLTemplateData{TableName: "`users`", IndexName: "`idx_email`", Columns: "`email`", Unique: true},
+			"CREATE UNIQUE INDEX `idx_email` ON `users` (`email`);"},
+	}
+	for _, c := range cases {
+		got, err := renderSQL(c.db, CreateIndexTemplate, c.data)
+		if err != nil {
+			t.Fatalf("Failed to render create index template: %v", err)
+		}
+		if got != c.expected {
+			t.Errorf("Expected %s, got %s", c.expected, got)
+		}
+	}
+}
+
+func TestRenderSQLUpdateColumn(t *testing.T) {
+	postgres := &PostgresDataBase{DataBase: DataBase{name: PostgresDB}}
+	column := SQLTemplateData{TableName: `"users"`, ColumnName: `"age"`, ColumnType: "BIGINT", Default: "0"}
+	cases := []struct {
+		typeChanged, nullableChanged, defaultChanged, nullable bool
+		expected                                               string
+	}{
+		{true, false, false, false, `ALTER TABLE "users" ALTER COLUMN "age" SET DATA TYPE BIGINT;`},
+		{false, true, false, true, `ALTER TABLE "users" ALTER COLUMN "age" DROP NOT NULL;`},
+		{false, false, true, false, `ALTER TABLE "users" ALTER COLUMN "age" SET DEFAULT 0;`},
+		{true, true, true, false, `ALTER TABLE "users" ALTER COLUMN "age" SET DATA TYPE BIGINT, ` +
+			`ALTER COLUMN "age" SET NOT NULL, ALTER COLUMN "age" SET DEFAULT 0;`},
+	}
+	for _, c := range cases {
+		data := column
+		data.TypeChanged, data.NullableChanged, data.DefaultChanged, data.Nullable = c.typeChanged, c.nullableChanged, c.defaultChanged, c.nullable
+		got, err := renderSQL(postgres, UpdateColumnTemplate, data)
+		if err != nil {
+			t.Fatalf("Failed to render update column template: %v", err)
+		}
+		if got != c.expected {
+			t.Errorf("Expected %s, got %s", c.expected, got)
+		}
+	}
+
+	column.Default = ""
+	column.DefaultChanged = true
+	if got, _ := renderSQL(postgres, UpdateColumnTemplate, column); got != `ALTER TABLE "users" ALTER COLUMN "age" DROP DEFAULT;` {
+		t.Errorf("Expected the default to be dropped, got %s", got)
+	}
+}
+
+func TestSetTemplate(t *testing.T) {
+	mariadb := &MariaDBDataBase{DataBase: DataBase{name: MariaDB}}
+	other := &MariaDBDataBase{DataBase: DataBase{name: MariaDB}}
+
+	err := mariadb.SetTemplate(CreateIndexTemplate, "CREATE {{if .Unique}}UNIQUE {{end}}INDEX {{.IndexName}} ON {{.TableName}} ({{.Columns}}) ALGORITHM=INPLACE;")
+	if err != nil {
+		t.Fatalf("Failed to set template: %v", err)
+	}
+	data := SQLTemplateData{TableName: "`t`", IndexName: "`i`", Columns: "`c`"}
+	if got, _ := renderSQL(mariadb, CreateIndexTemplate, data); got != "CREATE INDEX `i` ON `t` (`c`) ALGORITHM=INPLACE;" {
+		t.Errorf("Expected the override to be rendered, got %s", got)
+	}
+	if got, _ := renderSQL(other, CreateIndexTemplate, data); got != "CREATE INDEX `i` ON `t` (`c`);" {
+		t.Errorf("Expected other instances to keep the dialect template, got %s", got)
+	}
+
+	if err := mariadb.SetTemplate(CreateIndexTemplate, ""); err != nil {
+		t.Fatalf("Failed to reset template: %v", err)
+	}
+	if got, _ := renderSQL(mariadb, CreateIndexTemplate, data); got != "CREATE INDEX `i` ON `t` (`c`);" {
+		t.Errorf("Expected the reset to restore the dialect template, got %s", got)
+	}
+
+	if err := mariadb.SetTemplate(InsertTemplate, "INSERT INTO {{.TableName"); err == nil {
+		t.Errorf("Expected a parse error for a malformed template")
+	}
+	if err := mariadb.SetTemplate(InsertTemplate, "INSERT INTO {{.Table}}"); err != nil {
+		t.Fatalf("Failed to set template: %v", err)
+	}
+	if _, err := renderSQL(mariadb, InsertTemplate, data); err == nil {
+		t.Errorf("Expected a render error for an unknown field")
+	}
+}
+
+func TestRenderSQLConcurrentIndex(t *testing.T) {
+	postgres := &PostgresDataBase{DataBase: DataBase{name: PostgresDB}}
+	mariadb := &MariaDBDataBase{DataBase: DataBase{name: MariaDB}}
+	cases := []struct {
+		db       DBInterface
+		name     SQLTemplateName
+		data     SQLTemplateData
+		expected string
+	}{
+		{postgres, CreateIndexTemplate, SQLTemplateData{TableName: `"users"`, IndexName: `"idx_email"`, Columns: `"email"`, IfNotExists: true, Concurrently: true},
+			`CREATE INDEX CONCURRENTLY IF NOT EXISTS "idx_email" ON "users" ("email");`},
+		{postgres, DropIndexTemplate, SQLTemplateData{Schema: `"app"`, IndexName: `"idx_email"`, Concurrently: true},
+			`DROP INDEX CONCURRENTLY IF EXISTS "app"."idx_email";`},
+		{mariadb, CreateIndexTemplate, SQLTemplateData{TableName: "`users`", IndexName: "`idx_email`", Columns: "`email`", Concurrently: true},
+			"CREATE INDEX `idx_email` ON `users` (`email`) ALGORITHM=INPLACE LOCK=NONE;"},
+		{mariadb, DropIndexTemplate, SQLTemplateData{TableName: "`users`", IndexName: "`idx_email`", Concurrently: true},
+			"DROP INDEX `idx_email` ON `users` ALGORITHM=INPLACE LOCK=NONE;"},
+	}
+	for _, c := range cases {
+		got, err := renderSQL(c.db, c.name, c.data)
+		if err != nil || got != c.expected {
+			t.Errorf("Expected %s, got %s (%v)", c.expected, got, err)
+		}
+	}
+}
+
+func TestRenderSQLIndexMethod(t *testing.T) {
+	postgres := &PostgresDataBase{DataBase: DataBase{name: PostgresDB}}
+	mariadb := &MariaDBDataBase{DataBase: DataBase{name: MariaDB}}
+	cases := []struct {
+		db       DBInterface
+		method   string
+		expected string
+	}{
+		{postgres, "gin", `CREATE INDEX "idx_body" ON "docs" USING gin ("body");`},
+		{mariadb, "FULLTEXT", "CREATE FULLTEXT INDEX `idx_body` ON `docs` (`body`);"},
+		{mariadb, "SPATIAL", "CREATE SPATIAL INDEX `idx_body` ON `docs` (`body`);"},
+		{mariadb, "HASH", "CREATE INDEX `idx_body` ON `docs` (`body`) USING HASH;"},
+	}
+	for _, c := range cases {
+		data := SQLTemplateData{
+			TableName: c.db.QuoteIdentifier("docs"),
+			IndexName: c.db.QuoteIdentifier("idx_body"),
+			Columns:   c.db.QuoteIdentifier("body"),
+			Method:    c.method,
+		}
+		got, err := renderSQL(c.db, CreateIndexTemplate, data)
+		if err != nil || got != c.expected {
+			t.Errorf("Expected %s, got %s (%v)", c.expected, got, err)
+		}
+	}
+
+	if method, err := mariadb.IndexMethod("fulltext"); err != nil || method != "FULLTEXT" {
+		t.Errorf("Expected FULLTEXT, got %q (%v)", method, err)
+	}
+	if _, err := mariadb.IndexMethod("gin"); err == nil {
+		t.Errorf("Expected an error for an index type MariaDB does not support")
+	}
+}
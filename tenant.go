@@ -0,0 +1,135 @@
+package aaronsql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// TenantResolver maps requests to the namespace of their tenant: a PostgreSQL schema or a
+// MariaDB database. Tables without an explicit schema target the namespace resolved from the
+// context passed to their *Context methods.
+type TenantResolver interface {
+	// ResolveTenant returns the schema of the tenant of ctx, empty for the default schema.
+	ResolveTenant(ctx context.Context) (string, error)
+	// ListTenants returns the schemas of all tenants.
+	ListTenants(ctx context.Context) ([]string, error)
+}
+
+// SetTenantResolver routes the operations of tables of this database to the tenant schemas
+// resolved by resolver, nil disables tenant routing.
+func (d *DataBase) SetTenantResolver(resolver TenantResolver) {
+	d.tenantResolver = resolver
+}
+
+// TenantResolver returns the tenant resolver of this database, nil if none is set.
+func (d *DataBase) TenantResolver() TenantResolver {
+	return d.tenantResolver
+}
+
+// WithParallelism sets the number of tenants SyncAllTenants synchronizes at the same time.
+func WithParallelism(n int) SyncOption {
+	return func(o *syncOptions) {
+		if n > 0 {
+			o.parallelism = n
+		}
+	}
+}
+
+// TenantSyncResult is the outcome of synchronizing a table in the schema of one tenant.
+type TenantSyncResult struct {
+	Tenant   string
+	Err      error
+	Duration time.Duration
+}
+
+// TenantSyncReport collects the results of SyncAllTenants, ordered by tenant.
+type TenantSyncReport struct {
+	Results []TenantSyncResult
+}
+
+// Failed returns the results of the tenants which failed to synchronize.
+func (r *TenantSyncReport) Failed() []TenantSyncResult {
+	failed := make([]TenantSyncResult, 0)
+	for _, result := range r.Results {
+		if result.Err != nil {
+			failed = append(failed, result)
+		}
+	}
+	return failed
+}
+
+// Err joins the errors of all failed tenants, it is nil if every tenant succeeded.
+func (r *TenantSyncReport) Err() error {
+	var errs []error
+	for _, result := range r.Failed() {
+		errs = append(errs, fmt.Errorf("tenant %s: %w", result.Tenant, result.Err))
+	}
+	return errors.Join(errs...)
+}
+
+// SyncAllTenants synchronizes the table in the schema of every tenant listed by the tenant
+// resolver of the database. Tenants are synchronized concurrently as configured by
+// WithParallelism, a failing tenant does not stop the others. The report holds the result of
+// every tenant, the returned error joins the errors of the failed ones.
+func (t *Table) SyncAllTenants(ctx context.Context, opts ...SyncOption) (*TenantSyncReport, error) {
+	resolver := t.db.GetDB().tenantResolver
+	if resolver == nil {
+		return nil, fmt.Errorf("no tenant resolver set for database: %s", t.db.Name())
+	}
+	if t.schema != "" {
+		return nil, fmt.Errorf("table %s is pinned to schema %s and is not synchronized per tenant", t.name, t.schema)
+	}
+	tenants, err := resolver.ListTenants(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tenants: %w", err)
+	}
+	options := newSyncOptions(opts)
+	report := runTenants(ctx, tenants, options.parallelism, func(ctx context.Context, tenant string) error {
+		return t.syncSchema(ctx, tenant, options)
+	})
+	return report, report.Err()
+}
+
+// runTenants calls fn for every tenant with at most parallelism calls running at the same time.
+// Tenants which have not started when ctx is done are reported with the error of ctx.
+func runTenants(ctx context.Context, tenants []string, parallelism int, fn func(ctx context.Context, tenant string) error) *TenantSyncReport {
+	if parallelism < 1 {
+		parallelism = 1
+	}
+	report := &TenantSyncReport{Results: make([]TenantSyncResult, len(tenants))}
+	sem := make(chan struct{}, parallelism)
+	var wg sync.WaitGroup
+	for i, tenant := range tenants {
+		report.Results[i].Tenant = tenant
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			report.Results[i].Err = ctx.Err()
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			<-sem
+			report.Results[i].Err = err
+			continue
+		}
+		wg.Add(1)
+		go func(result *TenantSyncResult) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			start := time.Now()
+			result.Err = fn(ctx, result.Tenant)
+			result.Duration = time.Since(start)
+		}(&report.Results[i])
+	}
+	wg.Wait()
+	sort.SliceStable(report.Results, func(i, j int) bool {
+		return report.Results[i].Tenant < report.Results[j].Tenant
+	})
+	return report
+}
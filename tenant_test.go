@@ -0,0 +1,135 @@
+package aaronsql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+type tenantKey struct{}
+
+type testTenantResolver struct {
+	tenants []string
+}
+
+func (r *testTenantResolver) ResolveTenant(ctx context.Context) (string, error) {
+	tenant, _ := ctx.Value(tenantKey{}).(string)
+	if tenant == "unknown" {
+		return "", errors.New("unknown tenant")
+	}
+	return tenant, nil
+}
+
+func (r *testTenantResolver) ListTenants(ctx context.Context) ([]string, error) {
+	return r.tenants, nil
+}
+
+func TestTenantResolveSchema(t *testing.T) {
+	type account struct {
+		ID int64 `db:"name:id;primary"`
+	}
+	postgres := &PostgresDataBase{DataBase: DataBase{name: PostgresDB}}
+	postgres.SetSchema("public")
+	postgres.SetTenantResolver(&testTenantResolver{})
+	registerTestDB(t, "tenant_test", postgres)
+
+	table, err := NewTableFromStructWithDB(account{}, "accounts", "tenant_test")
+	if err != nil {
+		t.Fatalf("Failed to create table from struct: %v", err)
+	}
+	shared, err := NewTableFromStructWithDB(account{}, "plans", "tenant_test", WithSchema("shared"))
+	if err != nil {
+		t.Fatalf("Failed to create table from struct: %v", err)
+	}
+
+	tenantCtx := context.WithValue(context.Background(), tenantKey{}, "tenant_42")
+	cases := []struct {
+		table    *Table
+		ctx      context.Context
+		expected string
+	}{
+		{table, tenantCtx, "tenant_42"},
+		{table, context.Background(), "public"},
+		{shared, tenantCtx, "shared"},
+	}
+	for _, c := range cases {
+		schema, err := c.table.resolveSchema(c.ctx)
+		if err != nil || schema != c.expected {
+			t.Errorf("Expected table %s to resolve to schema %s, got %s (%v)", c.table.Name(), c.expected, schema, err)
+		}
+	}
+
+	unknownCtx := context.WithValue(context.Background(), tenantKey{}, "unknown")
+	if err := table.InsertContext(unknownCtx, &account{ID: 1}); err == nil {
+		t.Errorf("Expected resolver errors to be returned")
+	}
+	if _, err := shared.SyncAllTenants(context.Background()); err == nil {
+		t.Errorf("Expected tables pinned to a schema to be rejected by SyncAllTenants")
+	}
+}
+
+func TestTenantDropContext(t *testing.T) {
+	type account struct {
+		ID int64 `db:"name:id;primary"`
+	}
+	postgres := &PostgresDataBase{DataBase: DataBase{name: PostgresDB}}
+	postgres.SetTenantResolver(&testTenantResolver{})
+	conn := recordStatements(t, "tenant_drop_test", postgres)
+
+	table, err := NewTableFromStructWithDB(account{}, "accounts", "tenant_drop_test")
+	if err != nil {
+		t.Fatalf("Failed to create table from struct: %v", err)
+	}
+	if err := table.DropContext(context.WithValue(context.Background(), tenantKey{}, "tenant_42")); err != nil {
+		t.Fatalf("Failed to drop table: %v", err)
+	}
+	if len(conn.statements) != 1 || !strings.Contains(conn.statements[0], `"tenant_42"."accounts"`) {
+		t.Errorf("Expected the table of the tenant schema to be dropped, got %v", conn.statements)
+	}
+}
+
+func TestRunTenants(t *testing.T) {
+	tenants := []string{"t3", "t1", "t2", "t5", "t4"}
+	var running, maxRunning int32
+	report := runTenants(context.Background(), tenants, 2, func(ctx context.Context, tenant string) error {
+		n := atomic.AddInt32(&running, 1)
+		for {
+			m := atomic.LoadInt32(&maxRunning)
+			if n <= m || atomic.CompareAndSwapInt32(&maxRunning, m, n) {
+				break
+			}
+		}
+		defer atomic.AddInt32(&running, -1)
+		if tenant == "t2" {
+			return fmt.Errorf("boom")
+		}
+		return nil
+	})
+
+	if maxRunning > 2 {
+		t.Errorf("Expected at most 2 tenants at the same time, got %d", maxRunning)
+	}
+	if len(report.Results) != len(tenants) || report.Results[0].Tenant != "t1" || report.Results[4].Tenant != "t5" {
+		t.Errorf("Expected a result per tenant ordered by tenant, got %+v", report.Results)
+	}
+	failed := report.Failed()
+	if len(failed) != 1 || failed[0].Tenant != "t2" {
+		t.Errorf("Expected only t2 to fail, got %+v", failed)
+	}
+	if err := report.Err(); err == nil || err.Error() != "tenant t2: boom" {
+		t.Errorf("Unexpected report error: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	report = runTenants(ctx, tenants, 1, func(ctx context.Context, tenant string) error {
+		t.Errorf("Expected no tenant to start after cancellation, got %s", tenant)
+		return nil
+	})
+	if len(report.Failed()) != len(tenants) || !errors.Is(report.Failed()[0].Err, context.Canceled) {
+		t.Errorf("Expected every tenant to report the cancellation, got %+v", report.Results)
+	}
+}
@@ -0,0 +1,278 @@
+package aaronsql
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// SQLTyper is implemented by custom column types which know their SQL type for each dialect.
+// An empty string means the type has no mapping for the dialect.
+type SQLTyper interface {
+	SQLType(dialect DBName) string
+}
+
+// ValueConverter converts a struct field value to the value passed to the database driver.
+type ValueConverter func(value interface{}) interface{}
+
+type registeredType struct {
+	sqlType   string
+	converter ValueConverter
+}
+
+var (
+	typeRegistryLock sync.RWMutex
+	typeRegistry     = make(map[reflect.Type]map[DBName]registeredType)
+
+	valuerType   = reflect.TypeOf((*driver.Valuer)(nil)).Elem()
+	scannerType  = reflect.TypeOf((*sql.Scanner)(nil)).Elem()
+	sqlTyperType = reflect.TypeOf((*SQLTyper)(nil)).Elem()
+
+	uuidArrayType = reflect.TypeOf([16]byte{})
+	ipType        = reflect.TypeOf(net.IP{})
+	bigFloatType  = reflect.TypeOf(big.Float{})
+	bigRatType    = reflect.TypeOf(big.Rat{})
+	bigIntType    = reflect.TypeOf(big.Int{})
+)
+
+// RegisterType maps a Go type to a column type of the given dialect. The converter is optional,
+// when set it is used to convert field values before they are passed to the database driver.
+func RegisterType(t reflect.Type, dialect DBName, sqlType string, converter ValueConverter) {
+	if t == nil || sqlType == "" {
+		panic("register type requires a type and a sql type")
+	}
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	typeRegistryLock.Lock()
+	defer typeRegistryLock.Unlock()
+	if typeRegistry[t] == nil {
+		typeRegistry[t] = make(map[DBName]registeredType)
+	}
+	typeRegistry[t][dialect] = registeredType{sqlType: sqlType, converter: converter}
+}
+
+func lookupRegisteredType(t reflect.Type, dialect DBName) (registeredType, bool) {
+	typeRegistryLock.RLock()
+	defer typeRegistryLock.RUnlock()
+	rt, ok := typeRegistry[t][dialect]
+	return rt, ok
+}
+
+// implementsEither reports whether t or *t implements iface.
+func implementsEither(t reflect.Type, iface reflect.Type) bool {
+	return t.Implements(iface) || reflect.PointerTo(t).Implements(iface)
+}
+
+// isCustomValueType reports whether t converts itself through driver.Valuer or sql.Scanner.
+func isCustomValueType(t reflect.Type) bool {
+	return implementsEither(t, valuerType) || implementsEither(t, scannerType)
+}
+
+// resolveCustomType returns the column type of types which are not mapped by their kind.
+// The type tag takes precedence, followed by the RegisterType registry and the SQLTyper interface.
+func resolveCustomType(dialect DBName, actualType reflect.Type, tag map[string]string) (string, ValueConverter, bool) {
+	var converter ValueConverter
+	rt, registered := lookupRegisteredType(actualType, dialect)
+	if registered {
+		converter = rt.converter
+	}
+	if sqlType, ok := tag[TAG_TYPE]; ok && sqlType != "" {
+		return sqlType, converter, true
+	}
+	if registered {
+		return rt.sqlType, converter, true
+	}
+	if implementsEither(actualType, sqlTyperType) {
+		typer := reflect.New(actualType).Interface().(SQLTyper)
+		if sqlType := typer.SQLType(dialect); sqlType != "" {
+			return sqlType, nil, true
+		}
+	}
+	return "", nil, false
+}
+
+func unsupportedStructTypeError(t reflect.Type) error {
+	if isCustomValueType(t) {
+		return fmt.Errorf("custom type %s has no SQL type, use the %s tag, implement SQLType or call RegisterType", t.String(), TAG_TYPE)
+	}
+	return fmt.Errorf("unsupported struct type: %s", t.Name())
+}
+
+// isJSONType reports whether fields of type t are stored as JSON documents. Maps and slices
+// other than []byte are always JSON, structs only when tagged with json.
+func isJSONType(t reflect.Type, tag map[string]string) bool {
+	if _, ok := tag[TAG_JSON]; ok {
+		return true
+	}
+	if isCustomValueType(t) {
+		return false
+	}
+	switch t.Kind() {
+	case reflect.Map:
+		return true
+	case reflect.Slice:
+		return t.Elem().Kind() != reflect.Uint8
+	}
+	return false
+}
+
+// errorValue carries a conversion error to the driver, which reports it when the statement is executed.
+type errorValue struct {
+	err error
+}
+
+func (e errorValue) Value() (driver.Value, error) {
+	return nil, e.err
+}
+
+// jsonValueConverter marshals field values of JSON columns.
+func jsonValueConverter(value interface{}) interface{} {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return errorValue{err: fmt.Errorf("failed to marshal json value: %w", err)}
+	}
+	return string(data)
+}
+
+// jsonScanner unmarshals JSON column values into the struct field dst.
+type jsonScanner struct {
+	dst reflect.Value
+}
+
+func (s *jsonScanner) Scan(src interface{}) error {
+	var data []byte
+	switch v := src.(type) {
+	case nil:
+		s.dst.Set(reflect.Zero(s.dst.Type()))
+		return nil
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("cannot scan %T into json column", src)
+	}
+	return json.Unmarshal(data, s.dst.Addr().Interface())
+}
+
+// bigNumberValueConverter formats math/big numbers as exact decimal strings for NUMERIC columns.
+func bigNumberValueConverter(value interface{}) interface{} {
+	switch v := value.(type) {
+	case big.Float:
+		return v.Text('f', -1)
+	case big.Rat:
+		if v.IsInt() {
+			return v.Num().String()
+		}
+		return v.FloatString(ratScale(&v))
+	case big.Int:
+		return v.String()
+	}
+	return value
+}
+
+// ratScale returns the number of decimal digits needed to represent r exactly, capped at
+// 32 digits for fractions with an infinite decimal expansion.
+func ratScale(r *big.Rat) int {
+	scaled := new(big.Rat).Set(r)
+	ten := big.NewRat(10, 1)
+	for scale := 0; scale < 32; scale++ {
+		if scaled.IsInt() {
+			return scale
+		}
+		scaled.Mul(scaled, ten)
+	}
+	return 32
+}
+
+// TextParser parses the text representation of a column value into the struct field dst.
+type TextParser func(text string, dst reflect.Value) error
+
+// textScanner reads column values through their text representation, it is used for field
+// types the database/sql package cannot scan into, pointer fields are allocated as needed.
+type textScanner struct {
+	dst   reflect.Value
+	parse TextParser
+}
+
+func (s *textScanner) Scan(src interface{}) error {
+	dst := s.dst
+	if src == nil {
+		dst.Set(reflect.Zero(dst.Type()))
+		return nil
+	}
+	var text string
+	switch v := src.(type) {
+	case []byte:
+		text = string(v)
+	case string:
+		text = v
+	default:
+		text = fmt.Sprint(v)
+	}
+	if dst.Kind() == reflect.Ptr {
+		dst.Set(reflect.New(dst.Type().Elem()))
+		dst = dst.Elem()
+	}
+	return s.parse(text, dst)
+}
+
+// textParserFor returns the parser of field types which need one to be scanned, or nil.
+func textParserFor(actualType reflect.Type) TextParser {
+	if implementsEither(actualType, scannerType) {
+		return nil
+	}
+	switch {
+	case actualType == bigFloatType || actualType == bigRatType || actualType == bigIntType:
+		return parseBigNumberText
+	case actualType == ipType:
+		return parseIPText
+	case actualType.Kind() == reflect.Array && actualType.ConvertibleTo(uuidArrayType):
+		return parseUUIDText
+	}
+	return nil
+}
+
+func parseBigNumberText(text string, dst reflect.Value) error {
+	var ok bool
+	switch v := dst.Addr().Interface().(type) {
+	case *big.Float:
+		_, ok = v.SetString(text)
+	case *big.Rat:
+		_, ok = v.SetString(text)
+	case *big.Int:
+		_, ok = v.SetString(text, 10)
+	}
+	if !ok {
+		return fmt.Errorf("cannot parse %q as %s", text, dst.Type().String())
+	}
+	return nil
+}
+
+func parseIPText(text string, dst reflect.Value) error {
+	ip := net.ParseIP(strings.SplitN(text, "/", 2)[0])
+	if ip == nil {
+		return fmt.Errorf("cannot parse %q as an ip address", text)
+	}
+	dst.Set(reflect.ValueOf(ip))
+	return nil
+}
+
+func parseUUIDText(text string, dst reflect.Value) error {
+	raw, err := hex.DecodeString(strings.ReplaceAll(text, "-", ""))
+	if err != nil || len(raw) != 16 {
+		return fmt.Errorf("cannot parse %q as an uuid", text)
+	}
+	var b [16]byte
+	copy(b[:], raw)
+	dst.Set(reflect.ValueOf(b).Convert(dst.Type()))
+	return nil
+}
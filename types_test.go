@@ -0,0 +1,215 @@
+package aaronsql
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"math/big"
+	"net"
+	"reflect"
+	"testing"
+)
+
+type testMoney struct {
+	cents int64
+}
+
+func (m testMoney) Value() (driver.Value, error) {
+	return m.cents, nil
+}
+
+type testOrderID struct {
+	id string
+}
+
+func (o testOrderID) Value() (driver.Value, error) {
+	return o.id, nil
+}
+
+func (o testOrderID) SQLType(dialect DBName) string {
+	if dialect == PostgresDB {
+		return "UUID"
+	}
+	return "CHAR(36)"
+}
+
+type testStatus int
+
+func TestCustomColumnTypes(t *testing.T) {
+	RegisterType(reflect.TypeOf(testStatus(0)), MariaDB, "VARCHAR(16)", func(value interface{}) interface{} {
+		return []string{"active", "inactive"}[value.(testStatus)]
+	})
+
+	type order struct {
+		ID     testOrderID `db:"name:id;primary"`
+		Total  testMoney   `db:"name:total;type:BIGINT"`
+		Status *testStatus `db:"name:status"`
+	}
+
+	mariadb := &MariaDBDataBase{DataBase: DataBase{name: MariaDB}}
+	registerTestDB(t, "types_test", mariadb)
+	table, err := NewTableFromStructWithDB(order{}, "orders", "types_test")
+	if err != nil {
+		t.Fatalf("Failed to create table from struct: %v", err)
+	}
+	expected := map[string]string{"id": "CHAR(36)", "total": "BIGINT", "status": "VARCHAR(16)"}
+	for name, sqlType := range expected {
+		if got := table.Column(name).Type(); got != sqlType {
+			t.Errorf("Expected column %s to be %s, got %s", name, sqlType, got)
+		}
+	}
+
+	status := testStatus(1)
+	if got := table.Column("status").ConvertFromValueToSQL(&status); got != "inactive" {
+		t.Errorf("Expected converted status to be inactive, got %v", got)
+	}
+	if got := table.Column("status").ConvertFromValueToSQL((*testStatus)(nil)); got != nil {
+		t.Errorf("Expected nil status to convert to nil, got %v", got)
+	}
+
+	type untyped struct {
+		Total testMoney `db:"name:total"`
+	}
+	if _, err := NewTableFromStructWithDB(untyped{}, "untyped", "types_test"); err == nil {
+		t.Errorf("Expected an error for a custom type without SQL type")
+	}
+}
+
+type testPreferences struct {
+	Theme string `json:"theme"`
+}
+
+func TestJSONColumnTypes(t *testing.T) {
+	type profile struct {
+		ID          int64                  `db:"name:id;primary"`
+		Attributes  map[string]interface{} `db:"name:attributes"`
+		Labels      []uint32               `db:"name:labels"`
+		Preferences testPreferences        `db:"name:preferences;json"`
+		Avatar      []byte                 `db:"name:avatar"`
+	}
+
+	registerTestDB(t, "json_postgres", &PostgresDataBase{DataBase: DataBase{name: PostgresDB}})
+	registerTestDB(t, "json_mariadb", &MariaDBDataBase{DataBase: DataBase{name: MariaDB}})
+	for dbName, jsonType := range map[string]string{"json_postgres": "JSONB", "json_mariadb": "JSON"} {
+		table, err := NewTableFromStructWithDB(profile{}, "profiles", dbName)
+		if err != nil {
+			t.Fatalf("Failed to create table from struct: %v", err)
+		}
+		for _, name := range []string{"attributes", "labels", "preferences"} {
+			if got := table.Column(name).Type(); got != jsonType {
+				t.Errorf("Expected column %s on %s to be %s, got %s", name, dbName, jsonType, got)
+			}
+		}
+		if got := table.Column("avatar").Type(); got == jsonType {
+			t.Errorf("Expected []byte column on %s not to be JSON", dbName)
+		}
+	}
+
+	table, err := NewTableFromStructWithDB(profile{}, "profiles", "json_postgres")
+	if err != nil {
+		t.Fatalf("Failed to create table from struct: %v", err)
+	}
+	col := table.Column("preferences")
+	if got := col.ConvertFromValueToSQL(testPreferences{Theme: "dark"}); got != `{"theme":"dark"}` {
+		t.Errorf("Expected marshaled preferences, got %v", got)
+	}
+
+	var p profile
+	target := col.ScanTarget(reflect.ValueOf(&p).Elem().FieldByName("Preferences"))
+	if err := target.(interface{ Scan(interface{}) error }).Scan([]byte(`{"theme":"light"}`)); err != nil {
+		t.Fatalf("Failed to scan json value: %v", err)
+	}
+	if p.Preferences.Theme != "light" {
+		t.Errorf("Expected unmarshaled theme light, got %q", p.Preferences.Theme)
+	}
+}
+
+func TestPostgresNativeTypes(t *testing.T) {
+	type device struct {
+		ID      [16]byte  `db:"name:id;primary"`
+		Tags    []string  `db:"name:tags"`
+		Counts  []int64   `db:"name:counts"`
+		Address net.IP    `db:"name:address"`
+		Balance big.Rat   `db:"name:balance;precision:12,2"`
+		Price   float64   `db:"name:price;precision:10,3"`
+		Serial  string    `db:"name:serial;uuid"`
+		Meta    []string  `db:"name:meta;json"`
+		Scores  []float64 `db:"name:scores"`
+		Ranks   []int     `db:"name:ranks"`
+	}
+
+	registerTestDB(t, "native_postgres", &PostgresDataBase{DataBase: DataBase{name: PostgresDB}})
+	table, err := NewTableFromStructWithDB(device{}, "devices", "native_postgres")
+	if err != nil {
+		t.Fatalf("Failed to create table from struct: %v", err)
+	}
+	expected := map[string]string{
+		"id":      "UUID",
+		"tags":    "TEXT[]",
+		"counts":  "BIGINT[]",
+		"address": "INET",
+		"balance": "NUMERIC(12,2)",
+		"price":   "NUMERIC(10,3)",
+		"serial":  "UUID",
+		"meta":    "JSONB",
+		"scores":  "DOUBLE PRECISION[]",
+		"ranks":   "BIGINT[]",
+	}
+	for name, sqlType := range expected {
+		if got := table.Column(name).Type(); got != sqlType {
+			t.Errorf("Expected column %s to be %s, got %s", name, sqlType, got)
+		}
+	}
+
+	id := [16]byte{0x12, 0x34, 0x56, 0x78, 0x9a, 0xbc, 0xde, 0xf0, 0x12, 0x34, 0x56, 0x78, 0x9a, 0xbc, 0xde, 0xf0}
+	if got := table.Column("id").ConvertFromValueToSQL(id); got != "12345678-9abc-def0-1234-56789abcdef0" {
+		t.Errorf("Expected formatted uuid, got %v", got)
+	}
+	if got := table.Column("address").ConvertFromValueToSQL(net.ParseIP("10.0.0.1")); got != "10.0.0.1" {
+		t.Errorf("Expected formatted ip, got %v", got)
+	}
+	if got := table.Column("balance").ConvertFromValueToSQL(*big.NewRat(1234, 100)); got != "12.34" {
+		t.Errorf("Expected exact decimal, got %v", got)
+	}
+
+	var d device
+	fields := reflect.ValueOf(&d).Elem()
+	scans := map[string]interface{}{
+		"id":      "12345678-9abc-def0-1234-56789abcdef0",
+		"address": []byte("10.0.0.1"),
+		"balance": []byte("12.34"),
+		"ranks":   []byte("{1,2,3}"),
+	}
+	for name, src := range scans {
+		target := table.Column(name).ScanTarget(fields.FieldByIndex(table.fieldPaths[name]))
+		if err := target.(interface{ Scan(interface{}) error }).Scan(src); err != nil {
+			t.Fatalf("Failed to scan %s: %v", name, err)
+		}
+	}
+	if d.ID != id || !d.Address.Equal(net.ParseIP("10.0.0.1")) || d.Balance.Cmp(big.NewRat(1234, 100)) != 0 || !reflect.DeepEqual(d.Ranks, []int{1, 2, 3}) {
+		t.Errorf("Unexpected scanned values: %+v", d)
+	}
+}
+
+func TestPostgresColumnTypeIntrospection(t *testing.T) {
+	valid := func(v int64) sql.NullInt64 { return sql.NullInt64{Int64: v, Valid: true} }
+	cases := []struct {
+		udt      string
+		length   sql.NullInt64
+		prec     sql.NullInt64
+		scale    sql.NullInt64
+		expected string
+	}{
+		{udt: "_text", expected: "TEXT[]"},
+		{udt: "_int8", expected: "BIGINT[]"},
+		{udt: "uuid", expected: "UUID"},
+		{udt: "inet", expected: "INET"},
+		{udt: "numeric", prec: valid(12), scale: valid(2), expected: "NUMERIC(12,2)"},
+		{udt: "varchar", length: valid(100), expected: "VARCHAR(100)"},
+		{udt: "timestamptz", expected: "TIMESTAMP WITH TIME ZONE"},
+	}
+	for _, c := range cases {
+		if got := postgresColumnType(c.udt, c.length, c.prec, c.scale, sql.NullInt64{}); !sameSQLType(got, c.expected) {
+			t.Errorf("Expected %s to map to %s, got %s", c.udt, c.expected, got)
+		}
+	}
+}